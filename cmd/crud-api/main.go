@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -12,38 +14,108 @@ import (
 	"time"
 
 	config "github.com/Saidurbu/go-lang-crud/internal/config"
-	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+	"github.com/Saidurbu/go-lang-crud/internal/grading"
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/health"
+	"github.com/Saidurbu/go-lang-crud/internal/metrics"
 	"github.com/Saidurbu/go-lang-crud/internal/middleware"
+	"github.com/Saidurbu/go-lang-crud/internal/openapi"
+	"github.com/Saidurbu/go-lang-crud/internal/router"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
 	"github.com/Saidurbu/go-lang-crud/internal/storage/postgres"
+	"github.com/Saidurbu/go-lang-crud/internal/storage/sqlite"
+	"github.com/Saidurbu/go-lang-crud/internal/tlsconfig"
+	"github.com/Saidurbu/go-lang-crud/internal/tracing"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
 )
 
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.DBDriver {
+	case "sqlite":
+		return sqlite.New(cfg)
+	default:
+		return postgres.New(cfg)
+	}
+}
+
 func main() {
 
 	cfg := config.MustLoad()
 
-	storage, err := postgres.New(cfg)
+	appLogger := logger.New(cfg.Env)
+	slog.SetDefault(appLogger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scales, err := grading.New(cfg.Grading)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	store, err := newStorage(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := metrics.RegisterDBStats(cfg.DBDriver, store.DBStats); err != nil {
+		log.Fatal(err)
+	}
+	store = metrics.InstrumentStorage(store, cfg.DBDriver)
+	store = tracing.InstrumentStorage(store, cfg.DBDriver)
+
 	slog.Info("Database connection established", "Environment", slog.String("env", cfg.Env))
 
-	router := http.NewServeMux()
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go storage.RunPurge(purgeCtx, store, cfg.SoftDelete.Retention, cfg.SoftDelete.PurgeInterval)
 
-	router.HandleFunc("POST /api/registration", student.Registration(storage))
-	router.HandleFunc("POST /api/login", student.Login(storage))
+	healthState := &health.State{}
+	mux := router.New(cfg, store, scales, healthState)
+	if err := openapi.CheckRoutes(mux.Patterns()); err != nil {
+		log.Fatal(err)
+	}
 
-	router.HandleFunc("GET /api/profile", middleware.JWTAuth(student.GetProfile(storage)))
+	limit := func(next http.Handler) http.Handler { return next }
+	if cfg.RateLimit.Enabled {
+		limit = middleware.NewRateLimiter(cfg.RateLimit, cfg.RateLimit.Rate, cfg.RateLimit.Burst).Wrap
+	}
 
-	router.HandleFunc("GET /api/students", middleware.JWTAuth(student.GetList(storage)))
-	router.HandleFunc("POST /api/students", middleware.JWTAuth(student.New(storage)))
-	router.HandleFunc("GET /api/students/{id}", middleware.JWTAuth(student.GetById(storage)))
-	router.HandleFunc("PUT /api/students/{id}", middleware.JWTAuth(student.Update(storage)))
-	router.HandleFunc("DELETE /api/students/{id}", middleware.JWTAuth(student.Delete(storage)))
+	handler := middleware.MatchedRoute(mux)
+	handler = middleware.MaxBodyBytes(cfg.MaxBodyBytes)(handler)
+	handler = middleware.ClientCertAuth(cfg.TLS.ClientAdmins)(handler)
+	handler = limit(handler)
+	handler = middleware.CORS(cfg.CORS, mux.ServeMux)(handler)
+	handler = middleware.Compress(cfg.Compression)(handler)
+	handler = middleware.Metrics(handler)
+	handler = middleware.Tracing(handler)
+	handler = middleware.AccessLog(appLogger)(handler)
+	handler = middleware.RequestID(handler)
 
 	server := http.Server{
-		Addr:    cfg.Addr,
-		Handler: router,
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	certCtx, stopCertWatch := context.WithCancel(context.Background())
+	defer stopCertWatch()
+	if cfg.TLS.Enabled {
+		tlsConfig, reloader, err := tlsconfig.New(cfg.TLS)
+		if err != nil {
+			log.Fatal(err)
+		}
+		server.TLSConfig = tlsConfig
+		if !cfg.TLS.HTTP2 {
+			// A non-nil, empty map keeps net/http from negotiating h2.
+			server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		}
+		go reloader.Watch(certCtx, cfg.TLS.ReloadInterval)
 	}
 
 	fmt.Printf("Starting server on %s\n", cfg.Addr)
@@ -53,8 +125,13 @@ func main() {
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		err := server.ListenAndServe()
-		if err != nil {
+		var err error
+		if cfg.TLS.Enabled {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err)
 		}
 
@@ -64,6 +141,12 @@ func main() {
 
 	slog.Info("Shutting down server...")
 
+	healthState.ShutDown()
+	time.Sleep(cfg.DrainDelay)
+
+	stopPurge()
+	stopCertWatch()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -71,5 +154,9 @@ func main() {
 		slog.Error("Error shutting down server:", slog.String("error", err.Error()))
 	}
 
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Error flushing traces:", slog.String("error", err.Error()))
+	}
+
 	slog.Info("Server gracefully stopped")
 }
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/requestid"
+)
+
+const redacted = "[REDACTED]"
+
+type change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Record stores an audit entry for a mutation of targetID. before is nil for
+// creations and after is nil for deletions. Failures are logged rather than
+// returned so that a broken audit table never fails the mutation itself.
+func Record(storage storage.Storage, r *http.Request, actor, action string, targetID uint, before, after *types.Student) {
+	changes, err := json.Marshal(diffStudents(before, after))
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to encode audit changes", slog.String("error", err.Error()))
+		return
+	}
+
+	entry := types.AuditEntry{
+		Actor:     actor,
+		Action:    action,
+		TargetID:  targetID,
+		Changes:   changes,
+		RequestID: requestid.FromContext(r.Context()),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := storage.CreateAuditEntry(context.WithoutCancel(r.Context()), entry); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to record audit entry",
+			slog.String("action", action),
+			slog.Uint64("target_id", uint64(targetID)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// diffStudents returns the fields that differ between before and after.
+// Password hashes are never included; a changed password shows up as redacted.
+func diffStudents(before, after *types.Student) map[string]change {
+	b, a := snapshot(before), snapshot(after)
+
+	diff := make(map[string]change)
+	for field, beforeValue := range b {
+		if afterValue := a[field]; beforeValue != afterValue {
+			diff[field] = change{Before: beforeValue, After: afterValue}
+		}
+	}
+	for field, afterValue := range a {
+		if _, ok := b[field]; !ok {
+			diff[field] = change{Before: nil, After: afterValue}
+		}
+	}
+
+	if before != nil && after != nil && before.Password != after.Password {
+		diff["password"] = change{Before: redacted, After: redacted}
+	} else if (before == nil) != (after == nil) {
+		diff["password"] = change{Before: redactedIf(before), After: redactedIf(after)}
+	}
+
+	return diff
+}
+
+func snapshot(student *types.Student) map[string]any {
+	if student == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"name":  student.Name,
+		"email": student.Email,
+		"age":   student.Age,
+		"role":  student.Role,
+	}
+}
+
+func redactedIf(student *types.Student) any {
+	if student == nil {
+		return nil
+	}
+	return redacted
+}
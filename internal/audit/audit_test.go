@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+func TestDiffStudents(t *testing.T) {
+	ada := &types.Student{Name: "Ada", Email: "ada@example.com", Age: 20, Role: "student", Password: "hash1"}
+
+	renamed := *ada
+	renamed.Name = "Ada Lovelace"
+
+	promoted := *ada
+	promoted.Role = "admin"
+	promoted.Age = 21
+
+	newPassword := *ada
+	newPassword.Password = "hash2"
+
+	tests := []struct {
+		name   string
+		before *types.Student
+		after  *types.Student
+		want   map[string]change
+	}{
+		{
+			name:  "created",
+			after: ada,
+			want: map[string]change{
+				"name":     {Before: nil, After: "Ada"},
+				"email":    {Before: nil, After: "ada@example.com"},
+				"age":      {Before: nil, After: 20},
+				"role":     {Before: nil, After: "student"},
+				"password": {Before: nil, After: redacted},
+			},
+		},
+		{
+			name:   "deleted",
+			before: ada,
+			want: map[string]change{
+				"name":     {Before: "Ada", After: nil},
+				"email":    {Before: "ada@example.com", After: nil},
+				"age":      {Before: 20, After: nil},
+				"role":     {Before: "student", After: nil},
+				"password": {Before: redacted, After: nil},
+			},
+		},
+		{
+			name:   "unchanged",
+			before: ada,
+			after:  ada,
+			want:   map[string]change{},
+		},
+		{
+			name:   "renamed",
+			before: ada,
+			after:  &renamed,
+			want:   map[string]change{"name": {Before: "Ada", After: "Ada Lovelace"}},
+		},
+		{
+			name:   "several fields",
+			before: ada,
+			after:  &promoted,
+			want: map[string]change{
+				"age":  {Before: 20, After: 21},
+				"role": {Before: "student", After: "admin"},
+			},
+		},
+		{
+			name:   "password is redacted",
+			before: ada,
+			after:  &newPassword,
+			want:   map[string]change{"password": {Before: redacted, After: redacted}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffStudents(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("diffStudents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
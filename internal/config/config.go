@@ -5,23 +5,150 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type HTTPServer struct {
 	Addr string `yaml:"address" env-required:"true"`
+	// DrainDelay is how long the server keeps serving after readiness starts
+	// failing on shutdown, giving load balancers time to stop sending traffic.
+	DrainDelay        time.Duration `yaml:"drain_delay" env:"HTTP_DRAIN_DELAY" env-default:"5s"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" env-default:"5s"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" env-default:"15s"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" env-default:"30s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" env-default:"60s"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES" env-default:"1048576"`
+	MaxBodyBytes      int64         `yaml:"max_body_bytes" env:"HTTP_MAX_BODY_BYTES" env-default:"1048576"`
+	TLS               TLS           `yaml:"tls"`
+}
+
+// TLS makes the server terminate TLS itself. MinVersion is "1.0" to "1.3";
+// CipherSuites are IANA names and only affect TLS 1.2 and below. ClientAuth is
+// "none", "request", "require", "verify_if_given" or "require_and_verify";
+// verified client certificates are checked against ClientCAFile and their
+// email SAN (or common name) becomes the caller's identity, with ClientAdmins
+// granted the admin role. Certificate files are re-read when they change,
+// checked every ReloadInterval; zero or less turns reloading off.
+type TLS struct {
+	Enabled        bool          `yaml:"enabled" env:"TLS_ENABLED"`
+	CertFile       string        `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"TLS_KEY_FILE"`
+	MinVersion     string        `yaml:"min_version" env:"TLS_MIN_VERSION" env-default:"1.2"`
+	CipherSuites   []string      `yaml:"cipher_suites" env:"TLS_CIPHER_SUITES" env-separator:","`
+	ClientAuth     string        `yaml:"client_auth" env:"TLS_CLIENT_AUTH" env-default:"none"`
+	ClientCAFile   string        `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	ClientAdmins   []string      `yaml:"client_admins" env:"TLS_CLIENT_ADMINS" env-separator:","`
+	HTTP2          bool          `yaml:"http2" env:"TLS_HTTP2" env-default:"true"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL" env-default:"30s"`
+}
+
+// SoftDelete keeps deleted students for Retention before purging them, which
+// is checked every PurgeInterval; zero or less turns purging off.
+type SoftDelete struct {
+	Retention     time.Duration `yaml:"retention" env:"SOFT_DELETE_RETENTION" env-default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"SOFT_DELETE_PURGE_INTERVAL" env-default:"1h"`
+}
+
+// Tracing selects where spans are exported: "none", "otlp" (OTLP over HTTP to
+// Endpoint), "stdout", or "file" (JSON lines appended to FilePath).
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" env-default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+	FilePath    string  `yaml:"file_path" env:"TRACING_FILE_PATH" env-default:"traces.json"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" env-default:"crud-api"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// RateLimit configures token buckets per client. Rate is tokens per second and
+// Burst the bucket size; the Auth variants apply to login and registration.
+// KeyBy is "ip", "subject" (JWT email, falling back to IP) or "api_key"
+// (APIKeyHeader when it holds one of APIKeys, falling back to IP). With
+// TrustProxy the IP is the last X-Forwarded-For entry, the one the proxy in
+// front of the service appends.
+type RateLimit struct {
+	Enabled      bool     `yaml:"enabled" env:"RATE_LIMIT_ENABLED" env-default:"true"`
+	KeyBy        string   `yaml:"key_by" env:"RATE_LIMIT_KEY_BY" env-default:"ip"`
+	APIKeyHeader string   `yaml:"api_key_header" env:"RATE_LIMIT_API_KEY_HEADER" env-default:"X-API-Key"`
+	APIKeys      []string `yaml:"api_keys" env:"RATE_LIMIT_API_KEYS" env-separator:","`
+	TrustProxy   bool     `yaml:"trust_proxy" env:"RATE_LIMIT_TRUST_PROXY"`
+	Rate         float64  `yaml:"rate" env:"RATE_LIMIT_RATE" env-default:"10"`
+	Burst        int      `yaml:"burst" env:"RATE_LIMIT_BURST" env-default:"20"`
+	AuthRate     float64  `yaml:"auth_rate" env:"RATE_LIMIT_AUTH_RATE" env-default:"0.1"`
+	AuthBurst    int      `yaml:"auth_burst" env:"RATE_LIMIT_AUTH_BURST" env-default:"5"`
+}
+
+// CORS lists what browsers on other origins may do. An empty AllowedOrigins
+// disables CORS handling; "*" allows any origin.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" env-separator:","`
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" env-separator:"," env-default:"GET,POST,PUT,DELETE"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" env-separator:"," env-default:"Authorization,Content-Type,Accept,Accept-Language,X-Request-ID,Idempotency-Key"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" env-separator:"," env-default:"X-Request-ID,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After,Idempotent-Replayed"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" env-default:"10m"`
+}
+
+// Compression encodes responses of at least MinSize bytes whose media type is
+// in ContentTypes. Encodings lists "zstd", "br" and "gzip" in order of
+// preference when the client accepts several equally.
+type Compression struct {
+	Enabled      bool     `yaml:"enabled" env:"COMPRESSION_ENABLED" env-default:"true"`
+	MinSize      int      `yaml:"min_size" env:"COMPRESSION_MIN_SIZE" env-default:"1024"`
+	Encodings    []string `yaml:"encodings" env:"COMPRESSION_ENCODINGS" env-separator:"," env-default:"zstd,br,gzip"`
+	ContentTypes []string `yaml:"content_types" env:"COMPRESSION_CONTENT_TYPES" env-separator:"," env-default:"application/json,application/problem+json,application/xml,application/problem+xml,application/msgpack,application/cbor,text/plain,text/csv,application/x-ndjson"`
+}
+
+// Idempotency controls how long responses to requests carrying an
+// Idempotency-Key are kept for replay.
+type Idempotency struct {
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+}
+
+// Grading lists the scales grades can be recorded on, in addition to the
+// built-in "letter", "percentage" and "points" scales, which a scale of the
+// same name replaces. Kind is "letter" (grades are band letters),
+// "percentage" (0 to 100) or "points" (0 to MaxPoints); a numeric grade gets
+// the first band whose Min it reaches, so numeric scales need a band with
+// Min 0. Band Points are the grade points that count towards GPA.
+// DefaultScale applies when a grade names no scale.
+type Grading struct {
+	DefaultScale string         `yaml:"default_scale" env:"GRADING_DEFAULT_SCALE" env-default:"letter"`
+	Scales       []GradingScale `yaml:"scales"`
+}
+
+type GradingScale struct {
+	Name      string        `yaml:"name"`
+	Kind      string        `yaml:"kind"`
+	MaxPoints float64       `yaml:"max_points"`
+	Bands     []GradingBand `yaml:"bands"`
+}
+
+type GradingBand struct {
+	Letter string  `yaml:"letter"`
+	Min    float64 `yaml:"min"`
+	Points float64 `yaml:"points"`
 }
 
 type Config struct {
 	Env         string `yaml:"env" env:"ENV" env-required:"true"`
 	StoragePath string `yaml:"storage_path" env:"STORAGE_PATH" env-required:"true"`
 	HTTPServer  `yaml:"http_server" env:"HTTP_SERVER" env-required:"true"`
-	DBHost      string `yaml:"db_host" env:"DB_HOST" env-required:"true"`
-	DBPort      string `yaml:"db_port" env:"DB_PORT" env-required:"true"`
-	DBUser      string `yaml:"db_user" env:"DB_USER" env-required:"true"`
-	DBPassword  string `yaml:"db_password" env:"DB_PASSWORD" env-required:"true"`
-	DBName      string `yaml:"db_name" env:"DB_NAME" env-required:"true"`
+	DBHost      string      `yaml:"db_host" env:"DB_HOST" env-required:"true"`
+	DBPort      string      `yaml:"db_port" env:"DB_PORT" env-required:"true"`
+	DBUser      string      `yaml:"db_user" env:"DB_USER" env-required:"true"`
+	DBPassword  string      `yaml:"db_password" env:"DB_PASSWORD" env-required:"true"`
+	DBName      string      `yaml:"db_name" env:"DB_NAME" env-required:"true"`
+	DBDriver    string      `yaml:"db_driver" env:"DB_DRIVER" env-default:"postgres"`
+	SoftDelete  SoftDelete  `yaml:"soft_delete"`
+	Tracing     Tracing     `yaml:"tracing"`
+	RateLimit   RateLimit   `yaml:"rate_limit"`
+	CORS        CORS        `yaml:"cors"`
+	Compression Compression `yaml:"compression"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	Grading     Grading     `yaml:"grading"`
 }
 
 func MustLoad() *Config {
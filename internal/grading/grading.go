@@ -0,0 +1,200 @@
+// Package grading resolves recorded grades on configurable scales and
+// computes credit-weighted GPAs from them.
+package grading
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+)
+
+const (
+	KindLetter     = "letter"
+	KindPercentage = "percentage"
+	KindPoints     = "points"
+)
+
+// ErrInvalidGrade is returned when a value cannot be read on a scale.
+var ErrInvalidGrade = errors.New("invalid grade")
+
+type Band struct {
+	Letter string  `json:"letter"`
+	Min    float64 `json:"min"`
+	Points float64 `json:"points"`
+}
+
+// Scale turns recorded values into letters and grade points. Numeric scales
+// keep their bands sorted by descending Min.
+type Scale struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	MaxPoints float64 `json:"max_points,omitempty"`
+	Bands     []Band  `json:"bands"`
+}
+
+// letterBands is the usual 4.0 scale, which the percentage and points scales
+// reuse with thresholds.
+var letterBands = []Band{
+	{Letter: "A", Min: 93, Points: 4.0},
+	{Letter: "A-", Min: 90, Points: 3.7},
+	{Letter: "B+", Min: 87, Points: 3.3},
+	{Letter: "B", Min: 83, Points: 3.0},
+	{Letter: "B-", Min: 80, Points: 2.7},
+	{Letter: "C+", Min: 77, Points: 2.3},
+	{Letter: "C", Min: 73, Points: 2.0},
+	{Letter: "C-", Min: 70, Points: 1.7},
+	{Letter: "D+", Min: 67, Points: 1.3},
+	{Letter: "D", Min: 60, Points: 1.0},
+	{Letter: "F", Min: 0, Points: 0},
+}
+
+func builtin() []Scale {
+	letter := make([]Band, len(letterBands))
+	percentage := make([]Band, len(letterBands))
+	points := make([]Band, len(letterBands))
+	for i, band := range letterBands {
+		letter[i] = Band{Letter: band.Letter, Points: band.Points}
+		percentage[i] = band
+		points[i] = Band{Letter: band.Letter, Min: band.Min * 10, Points: band.Points}
+	}
+	return []Scale{
+		{Name: "letter", Kind: KindLetter, Bands: letter},
+		{Name: "percentage", Kind: KindPercentage, Bands: percentage},
+		{Name: "points", Kind: KindPoints, MaxPoints: 1000, Bands: points},
+	}
+}
+
+// Scales are the grading scales a server accepts grades on.
+type Scales struct {
+	byName       map[string]Scale
+	defaultScale string
+}
+
+// New validates the scales in cfg and merges them over the built-in ones.
+func New(cfg config.Grading) (*Scales, error) {
+	scales := &Scales{byName: make(map[string]Scale), defaultScale: cfg.DefaultScale}
+	for _, scale := range builtin() {
+		scales.byName[scale.Name] = scale
+	}
+
+	for _, c := range cfg.Scales {
+		scale := Scale{Name: c.Name, Kind: c.Kind, MaxPoints: c.MaxPoints, Bands: make([]Band, len(c.Bands))}
+		for i, band := range c.Bands {
+			scale.Bands[i] = Band{Letter: band.Letter, Min: band.Min, Points: band.Points}
+		}
+		if err := scale.validate(); err != nil {
+			return nil, fmt.Errorf("grading scale %q: %w", c.Name, err)
+		}
+		scales.byName[scale.Name] = scale
+	}
+
+	if _, ok := scales.byName[scales.defaultScale]; !ok {
+		return nil, fmt.Errorf("default grading scale %q is not defined", scales.defaultScale)
+	}
+	return scales, nil
+}
+
+func (s *Scale) validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(s.Bands) == 0 {
+		return errors.New("at least one band is required")
+	}
+
+	var limit float64
+	switch s.Kind {
+	case KindLetter:
+		seen := make(map[string]bool, len(s.Bands))
+		for _, band := range s.Bands {
+			key := strings.ToUpper(band.Letter)
+			if key == "" || seen[key] {
+				return fmt.Errorf("band letters must be unique and non-empty, got %q", band.Letter)
+			}
+			seen[key] = true
+		}
+		return nil
+	case KindPercentage:
+		limit = 100
+	case KindPoints:
+		if s.MaxPoints <= 0 {
+			return errors.New("max_points must be positive")
+		}
+		limit = s.MaxPoints
+	default:
+		return fmt.Errorf("unknown kind %q", s.Kind)
+	}
+
+	var floor bool
+	for _, band := range s.Bands {
+		if band.Letter == "" {
+			return errors.New("band letters must be non-empty")
+		}
+		if band.Min < 0 || band.Min > limit {
+			return fmt.Errorf("band %s min %g is outside 0 to %g", band.Letter, band.Min, limit)
+		}
+		floor = floor || band.Min == 0
+	}
+	// Without a band starting at 0 some valid scores would have no letter.
+	if !floor {
+		return errors.New("a band with min 0 is required")
+	}
+	sort.SliceStable(s.Bands, func(i, j int) bool { return s.Bands[i].Min > s.Bands[j].Min })
+	return nil
+}
+
+// Get returns the scale called name, or the default scale when name is empty.
+func (s *Scales) Get(name string) (Scale, bool) {
+	if name == "" {
+		name = s.defaultScale
+	}
+	scale, ok := s.byName[name]
+	return scale, ok
+}
+
+// List returns every scale, by name.
+func (s *Scales) List() []Scale {
+	list := make([]Scale, 0, len(s.byName))
+	for _, scale := range s.byName {
+		list = append(list, scale)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Resolve reads value on the scale, returning the normalised value with its
+// letter and grade points.
+func (s Scale) Resolve(value string) (normalised, letter string, points float64, err error) {
+	value = strings.TrimSpace(value)
+
+	if s.Kind == KindLetter {
+		for _, band := range s.Bands {
+			if strings.EqualFold(band.Letter, value) {
+				return band.Letter, band.Letter, band.Points, nil
+			}
+		}
+		return "", "", 0, fmt.Errorf("%q is not a grade on the %s scale: %w", value, s.Name, ErrInvalidGrade)
+	}
+
+	limit := 100.0
+	if s.Kind == KindPoints {
+		limit = s.MaxPoints
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(n) || n < 0 || n > limit {
+		return "", "", 0, fmt.Errorf("grades on the %s scale must be numbers from 0 to %g: %w", s.Name, limit, ErrInvalidGrade)
+	}
+
+	normalised = strconv.FormatFloat(n, 'f', -1, 64)
+	for _, band := range s.Bands {
+		if n >= band.Min {
+			return normalised, band.Letter, band.Points, nil
+		}
+	}
+	return "", "", 0, fmt.Errorf("%s is below every band of the %s scale: %w", normalised, s.Name, ErrInvalidGrade)
+}
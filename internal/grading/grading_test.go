@@ -0,0 +1,157 @@
+package grading
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+)
+
+func TestResolve(t *testing.T) {
+	scales, err := New(config.Grading{DefaultScale: "letter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		scale      string
+		value      string
+		normalised string
+		letter     string
+		points     float64
+		wantErr    bool
+	}{
+		{scale: "letter", value: "A", normalised: "A", letter: "A", points: 4},
+		{scale: "letter", value: " b+ ", normalised: "B+", letter: "B+", points: 3.3},
+		{scale: "letter", value: "E", wantErr: true},
+		{scale: "percentage", value: "93", normalised: "93", letter: "A", points: 4},
+		{scale: "percentage", value: "92.99", normalised: "92.99", letter: "A-", points: 3.7},
+		{scale: "percentage", value: "059.50", normalised: "59.5", letter: "F", points: 0},
+		{scale: "percentage", value: "0", normalised: "0", letter: "F", points: 0},
+		{scale: "percentage", value: "100", normalised: "100", letter: "A", points: 4},
+		{scale: "percentage", value: "100.1", wantErr: true},
+		{scale: "percentage", value: "-1", wantErr: true},
+		{scale: "percentage", value: "NaN", wantErr: true},
+		{scale: "percentage", value: "ninety", wantErr: true},
+		{scale: "points", value: "830", normalised: "830", letter: "B", points: 3},
+		{scale: "points", value: "1001", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scale+" "+tt.value, func(t *testing.T) {
+			scale, ok := scales.Get(tt.scale)
+			if !ok {
+				t.Fatalf("scale %q not found", tt.scale)
+			}
+
+			normalised, letter, points, err := scale.Resolve(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidGrade) {
+					t.Fatalf("Resolve(%q) error = %v, want ErrInvalidGrade", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if normalised != tt.normalised || letter != tt.letter || points != tt.points {
+				t.Fatalf("Resolve(%q) = %q, %q, %g, want %q, %q, %g",
+					tt.value, normalised, letter, points, tt.normalised, tt.letter, tt.points)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	passFail := []config.GradingBand{{Letter: "P", Min: 50, Points: 4}, {Letter: "F", Min: 0, Points: 0}}
+
+	tests := []struct {
+		name    string
+		cfg     config.Grading
+		wantErr string
+	}{
+		{
+			name: "built-in scales",
+			cfg:  config.Grading{DefaultScale: "percentage"},
+		},
+		{
+			name: "custom points scale",
+			cfg: config.Grading{DefaultScale: "lab", Scales: []config.GradingScale{
+				{Name: "lab", Kind: KindPoints, MaxPoints: 50, Bands: passFail},
+			}},
+		},
+		{
+			name:    "unknown default",
+			cfg:     config.Grading{DefaultScale: "missing"},
+			wantErr: `default grading scale "missing" is not defined`,
+		},
+		{
+			name: "no zero band",
+			cfg: config.Grading{DefaultScale: "letter", Scales: []config.GradingScale{
+				{Name: "strict", Kind: KindPercentage, Bands: []config.GradingBand{{Letter: "P", Min: 50, Points: 4}}},
+			}},
+			wantErr: "a band with min 0 is required",
+		},
+		{
+			name: "band above max points",
+			cfg: config.Grading{DefaultScale: "letter", Scales: []config.GradingScale{
+				{Name: "lab", Kind: KindPoints, MaxPoints: 40, Bands: passFail},
+			}},
+			wantErr: "band P min 50 is outside 0 to 40",
+		},
+		{
+			name: "points without max",
+			cfg: config.Grading{DefaultScale: "letter", Scales: []config.GradingScale{
+				{Name: "lab", Kind: KindPoints, Bands: passFail},
+			}},
+			wantErr: "max_points must be positive",
+		},
+		{
+			name: "duplicate letters",
+			cfg: config.Grading{DefaultScale: "letter", Scales: []config.GradingScale{
+				{Name: "pf", Kind: KindLetter, Bands: []config.GradingBand{{Letter: "P", Points: 4}, {Letter: "p"}}},
+			}},
+			wantErr: "band letters must be unique",
+		},
+		{
+			name: "unknown kind",
+			cfg: config.Grading{DefaultScale: "letter", Scales: []config.GradingScale{
+				{Name: "odd", Kind: "stars", Bands: passFail},
+			}},
+			wantErr: `unknown kind "stars"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("New() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("New() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomBandsAreSorted(t *testing.T) {
+	scales, err := New(config.Grading{DefaultScale: "lab", Scales: []config.GradingScale{{
+		Name: "lab", Kind: KindPoints, MaxPoints: 50,
+		Bands: []config.GradingBand{{Letter: "F", Min: 0}, {Letter: "A", Min: 45, Points: 4}, {Letter: "C", Min: 30, Points: 2}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scale, _ := scales.Get("")
+	for value, want := range map[string]string{"50": "A", "44": "C", "30": "C", "29.5": "F"} {
+		if _, letter, _, err := scale.Resolve(value); err != nil || letter != want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", value, letter, err, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package grading
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// Summary totals the graded credits of a term or a whole transcript. GPA is
+// the credit-weighted mean of grade points: QualityPoints over Credits.
+type Summary struct {
+	Credits       float64 `json:"credits"`
+	QualityPoints float64 `json:"quality_points"`
+	GPA           float64 `json:"gpa"`
+}
+
+func (s *Summary) add(credits, points float64) {
+	s.Credits += credits
+	s.QualityPoints += credits * points
+}
+
+func (s *Summary) finish() {
+	if s.Credits > 0 {
+		s.GPA = round(s.QualityPoints / s.Credits)
+	}
+	s.Credits = round(s.Credits)
+	s.QualityPoints = round(s.QualityPoints)
+}
+
+type Entry struct {
+	Code    string  `json:"code"`
+	Title   string  `json:"title"`
+	Credits float64 `json:"credits"`
+	Scale   string  `json:"scale"`
+	Value   string  `json:"value"`
+	Letter  string  `json:"letter"`
+	Points  float64 `json:"points"`
+}
+
+type Term struct {
+	Name    string  `json:"name"`
+	Courses []Entry `json:"courses"`
+	Summary Summary `json:"summary"`
+}
+
+type Transcript struct {
+	Student     types.StudentResponse `json:"student"`
+	Terms       []Term                `json:"terms"`
+	Cumulative  Summary               `json:"cumulative"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// BuildTranscript groups the graded courses of a student by term. Terms are
+// in the order the student first enrolled in one of their courses, courses
+// within a term by code. Only completed enrollments count, so a grade left on
+// an enrollment that was later dropped or reopened is ignored.
+func BuildTranscript(student types.StudentResponse, courses []types.StudentCourse, now time.Time) Transcript {
+	transcript := Transcript{Student: student, Terms: []Term{}, GeneratedAt: now}
+
+	started := make(map[string]time.Time)
+	byTerm := make(map[string]*Term)
+	for _, course := range courses {
+		grade := course.Grade
+		if grade == nil || course.Status != types.EnrollmentStatusCompleted {
+			continue
+		}
+
+		term, ok := byTerm[grade.Term]
+		if !ok {
+			term = &Term{Name: grade.Term}
+			byTerm[grade.Term] = term
+		}
+		if first, ok := started[grade.Term]; !ok || course.EnrolledAt.Before(first) {
+			started[grade.Term] = course.EnrolledAt
+		}
+
+		term.Courses = append(term.Courses, Entry{
+			Code:    course.Course.Code,
+			Title:   course.Course.Title,
+			Credits: course.Course.Credits,
+			Scale:   grade.Scale,
+			Value:   grade.Value,
+			Letter:  grade.Letter,
+			Points:  grade.Points,
+		})
+		term.Summary.add(course.Course.Credits, grade.Points)
+		transcript.Cumulative.add(course.Course.Credits, grade.Points)
+	}
+
+	for _, term := range byTerm {
+		sort.Slice(term.Courses, func(i, j int) bool { return term.Courses[i].Code < term.Courses[j].Code })
+		term.Summary.finish()
+		transcript.Terms = append(transcript.Terms, *term)
+	}
+	sort.Slice(transcript.Terms, func(i, j int) bool {
+		a, b := transcript.Terms[i].Name, transcript.Terms[j].Name
+		if !started[a].Equal(started[b]) {
+			return started[a].Before(started[b])
+		}
+		return a < b
+	})
+	transcript.Cumulative.finish()
+	return transcript
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,139 @@
+package grading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+func course(code string, credits float64, status string, enrolled time.Time, grade *types.Grade) types.StudentCourse {
+	return types.StudentCourse{
+		Status:     status,
+		EnrolledAt: enrolled,
+		Course:     types.Course{Code: code, Title: code, Credits: credits},
+		Grade:      grade,
+	}
+}
+
+func grade(term string, points float64) *types.Grade {
+	return &types.Grade{Term: term, Scale: "letter", Points: points}
+}
+
+func TestBuildTranscriptSummaries(t *testing.T) {
+	fall := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	spring := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	completed := types.EnrollmentStatusCompleted
+
+	tests := []struct {
+		name       string
+		courses    []types.StudentCourse
+		terms      []string
+		termGPAs   []float64
+		credits    float64
+		quality    float64
+		cumulative float64
+	}{
+		{
+			name:       "no courses",
+			terms:      []string{},
+			termGPAs:   []float64{},
+			cumulative: 0,
+		},
+		{
+			name: "weighted by credits",
+			courses: []types.StudentCourse{
+				course("CS101", 4, completed, fall, grade("Fall", 4)),
+				course("MA101", 1, completed, fall, grade("Fall", 0)),
+			},
+			terms:      []string{"Fall"},
+			termGPAs:   []float64{3.2},
+			credits:    5,
+			quality:    16,
+			cumulative: 3.2,
+		},
+		{
+			name: "terms in enrollment order",
+			courses: []types.StudentCourse{
+				course("CS201", 3, completed, spring, grade("Spring", 2)),
+				course("CS101", 3, completed, fall, grade("Fall", 4)),
+				course("CS102", 2, completed, fall, grade("Fall", 3)),
+			},
+			terms:      []string{"Fall", "Spring"},
+			termGPAs:   []float64{3.6, 2},
+			credits:    8,
+			quality:    24,
+			cumulative: 3,
+		},
+		{
+			name: "rounded to two places",
+			courses: []types.StudentCourse{
+				course("A", 1, completed, fall, grade("Fall", 4)),
+				course("B", 1, completed, fall, grade("Fall", 3.7)),
+				course("C", 1, completed, fall, grade("Fall", 3.3)),
+			},
+			terms:      []string{"Fall"},
+			termGPAs:   []float64{3.67},
+			credits:    3,
+			quality:    11,
+			cumulative: 3.67,
+		},
+		{
+			name: "zero credit courses do not divide by zero",
+			courses: []types.StudentCourse{
+				course("SEM", 0, completed, fall, grade("Fall", 4)),
+			},
+			terms:      []string{"Fall"},
+			termGPAs:   []float64{0},
+			cumulative: 0,
+		},
+		{
+			name: "ungraded, dropped and reopened enrollments are left off",
+			courses: []types.StudentCourse{
+				course("CS101", 3, completed, fall, grade("Fall", 4)),
+				course("CS102", 3, types.EnrollmentStatusEnrolled, fall, nil),
+				course("CS103", 3, types.EnrollmentStatusDropped, fall, grade("Fall", 0)),
+				course("CS104", 3, types.EnrollmentStatusEnrolled, spring, grade("Spring", 0)),
+			},
+			terms:      []string{"Fall"},
+			termGPAs:   []float64{4},
+			credits:    3,
+			quality:    12,
+			cumulative: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript := BuildTranscript(types.StudentResponse{ID: 1}, tt.courses, fall)
+
+			if len(transcript.Terms) != len(tt.terms) {
+				t.Fatalf("got %d terms, want %v", len(transcript.Terms), tt.terms)
+			}
+			for i, term := range transcript.Terms {
+				if term.Name != tt.terms[i] || term.Summary.GPA != tt.termGPAs[i] {
+					t.Errorf("term %d = %s GPA %g, want %s GPA %g", i, term.Name, term.Summary.GPA, tt.terms[i], tt.termGPAs[i])
+				}
+			}
+
+			want := Summary{Credits: tt.credits, QualityPoints: tt.quality, GPA: tt.cumulative}
+			if transcript.Cumulative != want {
+				t.Fatalf("cumulative = %+v, want %+v", transcript.Cumulative, want)
+			}
+		})
+	}
+}
+
+func TestBuildTranscriptSortsCoursesByCode(t *testing.T) {
+	fall := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	completed := types.EnrollmentStatusCompleted
+	transcript := BuildTranscript(types.StudentResponse{}, []types.StudentCourse{
+		course("MA101", 3, completed, fall, grade("Fall", 3)),
+		course("CS101", 3, completed, fall, grade("Fall", 4)),
+	}, fall)
+
+	courses := transcript.Terms[0].Courses
+	if courses[0].Code != "CS101" || courses[1].Code != "MA101" {
+		t.Fatalf("courses = %v, want CS101 then MA101", courses)
+	}
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+func GetList(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := types.AuditFilter{Actor: query.Get("actor")}
+
+		if target := query.Get("target"); target != "" {
+			targetId, err := strconv.ParseUint(target, 10, 64)
+			if err != nil {
+				response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery, fmt.Errorf("invalid target"))
+				return
+			}
+			filter.TargetID = uint(targetId)
+		}
+
+		if since := query.Get("since"); since != "" {
+			sinceTime, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery, fmt.Errorf("invalid since, expected RFC 3339 timestamp"))
+				return
+			}
+			filter.Since = sinceTime
+		}
+
+		entries, err := storage.GetAuditEntries(r.Context(), filter)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, entries)
+	}
+}
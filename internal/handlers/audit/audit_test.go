@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// filterStore remembers the filter it was asked for; every other storage
+// method is left nil.
+type filterStore struct {
+	storage.Storage
+	filter *types.AuditFilter
+}
+
+func (s filterStore) GetAuditEntries(ctx context.Context, filter types.AuditFilter) ([]types.AuditEntry, error) {
+	*s.filter = filter
+	return []types.AuditEntry{}, nil
+}
+
+func TestGetList(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   types.AuditFilter
+	}{
+		{name: "no filter", status: http.StatusOK},
+		{
+			name:   "all filters",
+			query:  "?target=7&actor=admin@example.com&since=2025-09-01T08:00:00Z",
+			status: http.StatusOK,
+			want: types.AuditFilter{
+				TargetID: 7,
+				Actor:    "admin@example.com",
+				Since:    time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{name: "invalid target", query: "?target=seven", status: http.StatusBadRequest},
+		{name: "negative target", query: "?target=-1", status: http.StatusBadRequest},
+		{name: "invalid since", query: "?since=2025-09-01", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got types.AuditFilter
+			w := httptest.NewRecorder()
+			GetList(filterStore{filter: &got})(w, httptest.NewRequest(http.MethodGet, "/api/audit"+tt.query, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if got.TargetID != tt.want.TargetID || got.Actor != tt.want.Actor || !got.Since.Equal(tt.want.Since) {
+				t.Fatalf("filter = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package course
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/request"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+func New(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var course types.Course
+		if problem := request.Decode(r, &course); problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		if err := validation.Struct(course); err != nil {
+			response.WriteValidationError(w, r, err.(validator.ValidationErrors))
+			return
+		}
+
+		id, err := storage.CreateCourse(r.Context(), course)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusCreated, map[string]interface{}{
+			"success": true,
+			"message": "course created",
+			"id":      id,
+		})
+	}
+}
+
+func GetList(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		courses, err := storage.GetCourses(r.Context())
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		if courses == nil {
+			courses = []types.Course{}
+		}
+		response.Render(w, r, http.StatusOK, courses)
+	}
+}
+
+func GetById(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		course, err := storage.GetCourse(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, course)
+	}
+}
+
+func Update(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		var course types.Course
+		if problem := request.Decode(r, &course); problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		if err := validation.Struct(course); err != nil {
+			response.WriteValidationError(w, r, err.(validator.ValidationErrors))
+			return
+		}
+
+		course.ID = id
+		if err := storage.UpdateCourse(r.Context(), course); err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "course updated"})
+	}
+}
+
+func Delete(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := storage.DeleteCourse(r.Context(), id); err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "course deleted"})
+	}
+}
+
+// StudentCourses lists the courses a student is or was enrolled in, with the
+// status of each enrollment.
+func StudentCourses(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		if !authorizeStudent(w, r, storage, id) {
+			return
+		}
+
+		courses, err := storage.GetStudentCourses(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, courses)
+	}
+}
+
+// pathID parses the {id} path value, writing a 400 problem when it is not
+// a positive integer.
+func pathID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidID, fmt.Errorf("invalid id"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// authorizeStudent lets admins act on any student and everyone else only on
+// themselves, writing a 403 problem otherwise.
+func authorizeStudent(w http.ResponseWriter, r *http.Request, store storage.Storage, studentID uint) bool {
+	if isAdmin(r) {
+		return true
+	}
+
+	callerID, ok := callerStudentID(w, r, store)
+	if !ok {
+		return false
+	}
+	if callerID != studentID {
+		writeForbidden(w, r)
+		return false
+	}
+	return true
+}
+
+func isAdmin(r *http.Request) bool {
+	role, _ := r.Context().Value(student.RoleContextKey()).(string)
+	return role == types.RoleAdmin
+}
+
+// callerStudentID looks up the student the caller is signed in as, writing a
+// 403 problem when they have no student record.
+func callerStudentID(w http.ResponseWriter, r *http.Request, store storage.Storage) (uint, bool) {
+	email, _ := r.Context().Value(student.EmailContextKey()).(string)
+	caller, err := store.GetStudentByEmail(r.Context(), email)
+	if errors.Is(err, storage.ErrNotFound) {
+		writeForbidden(w, r)
+		return 0, false
+	}
+	if err != nil {
+		response.WriteStorageError(w, r, err)
+		return 0, false
+	}
+	return caller.ID, true
+}
+
+func writeForbidden(w http.ResponseWriter, r *http.Request) {
+	response.WriteError(w, r, http.StatusForbidden, response.CodeForbidden,
+		errors.New("only admins may act for other students"))
+}
@@ -0,0 +1,263 @@
+package course
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// rosterStore holds a fixed set of students, their enrollments and courses;
+// every other storage method is left nil.
+type rosterStore struct {
+	storage.Storage
+	students    map[uint]types.Student
+	enrollments map[uint]types.Enrollment
+	courses     map[uint][]types.StudentCourse
+}
+
+func newRosterStore() *rosterStore {
+	return &rosterStore{
+		students: map[uint]types.Student{
+			1: {ID: 1, Name: "Ada Lovelace", Email: "ada@example.com", Age: 36, Role: types.RoleStudent},
+			2: {ID: 2, Name: "Alan Turing", Email: "alan@example.com", Age: 41, Role: types.RoleStudent},
+		},
+		enrollments: map[uint]types.Enrollment{
+			5: {ID: 5, StudentID: 1, CourseID: 3, Status: types.EnrollmentStatusEnrolled},
+			6: {ID: 6, StudentID: 2, CourseID: 3, Status: types.EnrollmentStatusCompleted},
+		},
+		courses: make(map[uint][]types.StudentCourse),
+	}
+}
+
+func (s *rosterStore) GetStudentById(ctx context.Context, id uint, includeDeleted bool) (types.Student, error) {
+	st, ok := s.students[id]
+	if !ok {
+		return types.Student{}, fmt.Errorf("student %d: %w", id, storage.ErrNotFound)
+	}
+	return st, nil
+}
+
+func (s *rosterStore) GetStudentByEmail(ctx context.Context, email string) (types.Student, error) {
+	for _, st := range s.students {
+		if strings.EqualFold(st.Email, email) {
+			return st, nil
+		}
+	}
+	return types.Student{}, fmt.Errorf("student %q: %w", email, storage.ErrNotFound)
+}
+
+func (s *rosterStore) GetStudentCourses(ctx context.Context, studentID uint) ([]types.StudentCourse, error) {
+	if _, ok := s.students[studentID]; !ok {
+		return nil, fmt.Errorf("student %d: %w", studentID, storage.ErrNotFound)
+	}
+	return s.courses[studentID], nil
+}
+
+func (s *rosterStore) CreateEnrollment(ctx context.Context, studentID uint, courseID uint) (uint, error) {
+	if _, ok := s.students[studentID]; !ok {
+		return 0, fmt.Errorf("student %d: %w", studentID, storage.ErrNotFound)
+	}
+	return 10, nil
+}
+
+func (s *rosterStore) GetEnrollment(ctx context.Context, id uint) (types.Enrollment, error) {
+	enrollment, ok := s.enrollments[id]
+	if !ok {
+		return types.Enrollment{}, fmt.Errorf("enrollment %d: %w", id, storage.ErrNotFound)
+	}
+	return enrollment, nil
+}
+
+func (s *rosterStore) GetEnrollments(ctx context.Context, filter types.EnrollmentFilter) ([]types.Enrollment, error) {
+	var enrollments []types.Enrollment
+	for _, id := range slices.Sorted(maps.Keys(s.enrollments)) {
+		if e := s.enrollments[id]; filter.StudentID == 0 || e.StudentID == filter.StudentID {
+			enrollments = append(enrollments, e)
+		}
+	}
+	return enrollments, nil
+}
+
+// as attaches the identity JWTAuth would have put on the request.
+func as(r *http.Request, email, role string) *http.Request {
+	ctx := context.WithValue(r.Context(), student.EmailContextKey(), email)
+	ctx = context.WithValue(ctx, student.RoleContextKey(), role)
+	return r.WithContext(ctx)
+}
+
+func TestAuthorizeStudent(t *testing.T) {
+	tests := []struct {
+		name         string
+		email        string
+		role         string
+		studentID    string
+		status       int
+		enrollStatus int
+	}{
+		{
+			name: "self", email: "ada@example.com", role: types.RoleStudent, studentID: "1",
+			status: http.StatusOK, enrollStatus: http.StatusCreated,
+		},
+		{
+			name: "self with other casing", email: "ADA@example.com", role: types.RoleStudent, studentID: "1",
+			status: http.StatusOK, enrollStatus: http.StatusCreated,
+		},
+		{
+			name: "other student", email: "ada@example.com", role: types.RoleStudent, studentID: "2",
+			status: http.StatusForbidden, enrollStatus: http.StatusForbidden,
+		},
+		{
+			name: "caller without an account", email: "grace@example.com", role: types.RoleStudent, studentID: "1",
+			status: http.StatusForbidden, enrollStatus: http.StatusForbidden,
+		},
+		{
+			name: "student asking for an unknown student", email: "ada@example.com", role: types.RoleStudent, studentID: "99",
+			status: http.StatusForbidden, enrollStatus: http.StatusForbidden,
+		},
+		{
+			name: "admin", email: "root@example.com", role: types.RoleAdmin, studentID: "2",
+			status: http.StatusOK, enrollStatus: http.StatusCreated,
+		},
+		{
+			name: "admin asking for an unknown student", email: "root@example.com", role: types.RoleAdmin, studentID: "99",
+			status: http.StatusNotFound, enrollStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newRosterStore()
+
+			r := httptest.NewRequest(http.MethodGet, "/api/students/"+tt.studentID+"/courses", nil)
+			r.SetPathValue("id", tt.studentID)
+			w := httptest.NewRecorder()
+			StudentCourses(store)(w, as(r, tt.email, tt.role))
+			if w.Code != tt.status {
+				t.Errorf("StudentCourses: status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+
+			body := fmt.Sprintf(`{"student_id":%s,"course_id":3}`, tt.studentID)
+			r = httptest.NewRequest(http.MethodPost, "/api/enrollments", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			w = httptest.NewRecorder()
+			Enroll(store)(w, as(r, tt.email, tt.role))
+			if w.Code != tt.enrollStatus {
+				t.Errorf("Enroll: status = %d, want %d: %s", w.Code, tt.enrollStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestGetEnrollments(t *testing.T) {
+	tests := []struct {
+		name   string
+		email  string
+		role   string
+		query  string
+		status int
+		want   []uint
+	}{
+		{name: "student sees only their own", email: "ada@example.com", role: types.RoleStudent, status: http.StatusOK, want: []uint{5}},
+		{name: "student filtering by themselves", email: "ada@example.com", role: types.RoleStudent, query: "?student=1", status: http.StatusOK, want: []uint{5}},
+		{name: "student filtering by another student", email: "ada@example.com", role: types.RoleStudent, query: "?student=2", status: http.StatusForbidden},
+		{name: "caller without an account", email: "grace@example.com", role: types.RoleStudent, status: http.StatusForbidden},
+		{name: "admin sees everyone's", email: "root@example.com", role: types.RoleAdmin, status: http.StatusOK, want: []uint{5, 6}},
+		{name: "admin filtering by student", email: "root@example.com", role: types.RoleAdmin, query: "?student=2", status: http.StatusOK, want: []uint{6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/enrollments"+tt.query, nil)
+			w := httptest.NewRecorder()
+			GetEnrollments(newRosterStore())(w, as(r, tt.email, tt.role))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var enrollments []types.Enrollment
+			if err := json.Unmarshal(w.Body.Bytes(), &enrollments); err != nil {
+				t.Fatal(err)
+			}
+			var got []uint
+			for _, e := range enrollments {
+				got = append(got, e.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("enrollments = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEnrollment(t *testing.T) {
+	tests := []struct {
+		name   string
+		email  string
+		role   string
+		id     string
+		status int
+	}{
+		{name: "own enrollment", email: "ada@example.com", role: types.RoleStudent, id: "5", status: http.StatusOK},
+		{name: "another student's enrollment", email: "ada@example.com", role: types.RoleStudent, id: "6", status: http.StatusForbidden},
+		{name: "admin", email: "root@example.com", role: types.RoleAdmin, id: "6", status: http.StatusOK},
+		{name: "unknown enrollment", email: "ada@example.com", role: types.RoleStudent, id: "99", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/enrollments/"+tt.id, nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			GetEnrollment(newRosterStore())(w, as(r, tt.email, tt.role))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestPathID(t *testing.T) {
+	tests := []struct {
+		id     string
+		want   uint
+		wantOK bool
+	}{
+		{id: "1", want: 1, wantOK: true},
+		{id: "42", want: 42, wantOK: true},
+		{id: "0"},
+		{id: "-1"},
+		{id: "abc"},
+		{id: ""},
+		{id: "18446744073709551616"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/students/x/courses", nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			got, ok := pathID(w, r)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("pathID(%q) = %d, %v, want %d, %v", tt.id, got, ok, tt.want, tt.wantOK)
+			}
+			if !ok && w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package course
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/request"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+type enrollmentInput struct {
+	StudentID uint `json:"student_id" validate:"required"`
+	CourseID  uint `json:"course_id" validate:"required"`
+}
+
+type statusInput struct {
+	Status string `json:"status" validate:"required,oneof=enrolled dropped completed"`
+}
+
+// Enroll places a student in a course, answering 409 when the course is full
+// or the student is already in it. Students may only enroll themselves.
+func Enroll(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input enrollmentInput
+		if problem := request.Decode(r, &input); problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		if err := validation.Struct(input); err != nil {
+			response.WriteValidationError(w, r, err.(validator.ValidationErrors))
+			return
+		}
+
+		if !authorizeStudent(w, r, storage, input.StudentID) {
+			return
+		}
+
+		id, err := storage.CreateEnrollment(r.Context(), input.StudentID, input.CourseID)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusCreated, map[string]interface{}{
+			"success": true,
+			"message": "student enrolled",
+			"id":      id,
+		})
+	}
+}
+
+// GetEnrollments lists enrollments matching the query. Students only ever see
+// their own; asking for another student's is forbidden.
+func GetEnrollments(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := types.EnrollmentFilter{Status: query.Get("status")}
+		switch filter.Status {
+		case "", types.EnrollmentStatusEnrolled, types.EnrollmentStatusDropped, types.EnrollmentStatusCompleted:
+		default:
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery, fmt.Errorf("invalid status"))
+			return
+		}
+
+		for name, dst := range map[string]*uint{"student": &filter.StudentID, "course": &filter.CourseID} {
+			value := query.Get(name)
+			if value == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery, fmt.Errorf("invalid %s", name))
+				return
+			}
+			*dst = uint(id)
+		}
+
+		if !isAdmin(r) {
+			callerID, ok := callerStudentID(w, r, storage)
+			if !ok {
+				return
+			}
+			if filter.StudentID != 0 && filter.StudentID != callerID {
+				writeForbidden(w, r)
+				return
+			}
+			filter.StudentID = callerID
+		}
+
+		enrollments, err := storage.GetEnrollments(r.Context(), filter)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		if enrollments == nil {
+			enrollments = []types.Enrollment{}
+		}
+		response.Render(w, r, http.StatusOK, enrollments)
+	}
+}
+
+// GetEnrollment shows one enrollment to an admin or the enrolled student.
+func GetEnrollment(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		enrollment, err := storage.GetEnrollment(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		if !authorizeStudent(w, r, storage, enrollment.StudentID) {
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, enrollment)
+	}
+}
+
+// UpdateEnrollment changes an enrollment's status. Moving it back to
+// enrolled needs a free seat like a new enrollment does.
+func UpdateEnrollment(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		var input statusInput
+		if problem := request.Decode(r, &input); problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		if err := validation.Struct(input); err != nil {
+			response.WriteValidationError(w, r, err.(validator.ValidationErrors))
+			return
+		}
+
+		if err := storage.UpdateEnrollmentStatus(r.Context(), id, input.Status); err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "enrollment updated"})
+	}
+}
+
+func DeleteEnrollment(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := storage.DeleteEnrollment(r.Context(), id); err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "enrollment deleted"})
+	}
+}
@@ -0,0 +1,120 @@
+package course
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Saidurbu/go-lang-crud/internal/grading"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/request"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+type gradeInput struct {
+	Term  string `json:"term" validate:"required,max=40"`
+	Scale string `json:"scale" validate:"max=40"`
+	Value string `json:"value" validate:"required,max=20"`
+}
+
+// SetGrade records the grade of an enrollment on the named scale, or the
+// default one, replacing any earlier grade and completing the enrollment.
+func SetGrade(storage storage.Storage, scales *grading.Scales) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		var input gradeInput
+		if problem := request.Decode(r, &input); problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		if err := validation.Struct(input); err != nil {
+			response.WriteValidationError(w, r, err.(validator.ValidationErrors))
+			return
+		}
+
+		scale, ok := scales.Get(input.Scale)
+		if !ok {
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeValidationFailed,
+				fmt.Errorf("unknown grading scale %q", input.Scale))
+			return
+		}
+		value, letter, points, err := scale.Resolve(input.Value)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeValidationFailed, err)
+			return
+		}
+
+		grade, err := storage.SetGrade(r.Context(), types.Grade{
+			EnrollmentID: id,
+			Term:         input.Term,
+			Scale:        scale.Name,
+			Value:        value,
+			Letter:       letter,
+			Points:       points,
+		})
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, grade)
+	}
+}
+
+// GetGrade shows the grade of an enrollment to an admin or the enrolled
+// student.
+func GetGrade(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		enrollment, err := storage.GetEnrollment(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+		if !authorizeStudent(w, r, storage, enrollment.StudentID) {
+			return
+		}
+
+		grade, err := storage.GetGrade(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, grade)
+	}
+}
+
+func DeleteGrade(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := storage.DeleteGrade(r.Context(), id); err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "grade deleted"})
+	}
+}
+
+// GradingScales lists the scales grades can be recorded on.
+func GradingScales(scales *grading.Scales) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.Render(w, r, http.StatusOK, scales.List())
+	}
+}
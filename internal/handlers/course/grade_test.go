@@ -0,0 +1,44 @@
+package course
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// GetGrade gives every enrollment of the roster an A.
+func (s *rosterStore) GetGrade(ctx context.Context, enrollmentID uint) (types.Grade, error) {
+	return types.Grade{EnrollmentID: enrollmentID, Term: "Fall 2025", Scale: "letter", Value: "A", Letter: "A", Points: 4}, nil
+}
+
+func TestGetGrade(t *testing.T) {
+	tests := []struct {
+		name   string
+		email  string
+		role   string
+		id     string
+		status int
+	}{
+		{name: "own grade", email: "ada@example.com", role: types.RoleStudent, id: "5", status: http.StatusOK},
+		{name: "another student's grade", email: "ada@example.com", role: types.RoleStudent, id: "6", status: http.StatusForbidden},
+		{name: "caller without an account", email: "grace@example.com", role: types.RoleStudent, id: "5", status: http.StatusForbidden},
+		{name: "admin", email: "root@example.com", role: types.RoleAdmin, id: "6", status: http.StatusOK},
+		{name: "unknown enrollment", email: "root@example.com", role: types.RoleAdmin, id: "99", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/enrollments/"+tt.id+"/grade", nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			GetGrade(newRosterStore())(w, as(r, tt.email, tt.role))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package course
+
+import (
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/grading"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/codec"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/pdf"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+// Transcript renders a student's graded courses by term with per-term and
+// cumulative GPAs. It is a PDF download when ?format=pdf is given or the
+// client only accepts application/pdf, and negotiated like other responses
+// otherwise.
+func Transcript(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+
+		if !authorizeStudent(w, r, storage, id) {
+			return
+		}
+
+		asPDF, problem := wantsPDF(r)
+		if problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		student, err := storage.GetStudentById(r.Context(), id, false)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+		courses, err := storage.GetStudentCourses(r.Context(), id)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		transcript := grading.BuildTranscript(types.StudentResponse{
+			ID:        student.ID,
+			Name:      student.Name,
+			Email:     student.Email,
+			Age:       student.Age,
+			CreatedAt: student.CreatedAt,
+			UpdatedAt: student.UpdatedAt,
+		}, courses, time.Now().UTC())
+
+		if !asPDF {
+			response.Render(w, r, http.StatusOK, transcript)
+			return
+		}
+
+		filename := fmt.Sprintf("transcript-%d.pdf", student.ID)
+		w.Header().Set("Content-Type", pdf.ContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Add("Vary", "Accept")
+		if _, err := renderTranscript(transcript).WriteTo(w); err != nil {
+			logger.FromContext(r.Context()).Error("Transcript download failed", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// wantsPDF reports whether the transcript should be a PDF, either asked for
+// with ?format=pdf or because application/pdf is the only acceptable type.
+func wantsPDF(r *http.Request) (bool, *response.Problem) {
+	switch r.URL.Query().Get("format") {
+	case "pdf":
+		return true, nil
+	case "":
+	default:
+		return false, response.NewProblem(http.StatusBadRequest, response.CodeInvalidQuery, "invalid format, expected pdf")
+	}
+
+	accept := r.Header.Get("Accept")
+	if _, ok := codec.Negotiate(accept); ok {
+		return false, nil
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == pdf.ContentType && params["q"] != "0" {
+			return true, nil
+		}
+	}
+	// Let Render answer 406.
+	return false, nil
+}
+
+// Transcript page layout, in points.
+const (
+	marginLeft   = 50.0
+	marginRight  = pdf.PageWidth - 50
+	marginTop    = 60.0
+	marginBottom = pdf.PageHeight - 60
+	lineHeight   = 14.0
+
+	columnCourse  = 110.0
+	columnCredits = 400.0
+	columnGrade   = 420.0
+	columnScore   = 470.0
+)
+
+// transcriptWriter lays a transcript out top to bottom, starting a new page
+// whenever the next line would not fit.
+type transcriptWriter struct {
+	doc     *pdf.Document
+	title   string
+	y       float64
+	pageNum int
+}
+
+func renderTranscript(t grading.Transcript) *pdf.Document {
+	tw := &transcriptWriter{
+		doc:   pdf.New("Transcript of " + t.Student.Name),
+		title: "Transcript of " + t.Student.Name,
+	}
+	tw.newPage()
+
+	tw.doc.Text(marginLeft, tw.y, pdf.Bold, 18, "Academic Transcript")
+	tw.y += 26
+	for _, line := range []string{
+		t.Student.Name,
+		t.Student.Email,
+		fmt.Sprintf("Student ID %d", t.Student.ID),
+		"Generated " + t.GeneratedAt.Format("2 January 2006 15:04 MST"),
+	} {
+		tw.doc.Text(marginLeft, tw.y, pdf.Regular, 10, line)
+		tw.y += lineHeight
+	}
+	tw.rule(1)
+
+	if len(t.Terms) == 0 {
+		tw.doc.Text(marginLeft, tw.y, pdf.Regular, 10, "No graded courses.")
+		return tw.doc
+	}
+
+	for _, term := range t.Terms {
+		tw.need(3)
+		tw.y += 6
+		tw.doc.Text(marginLeft, tw.y, pdf.Bold, 12, term.Name)
+		tw.y += lineHeight + 2
+		tw.header()
+
+		for _, course := range term.Courses {
+			if tw.need(1) {
+				tw.header()
+			}
+			tw.doc.Text(marginLeft, tw.y, pdf.Regular, 9, course.Code)
+			tw.doc.Text(columnCourse, tw.y, pdf.Regular, 9, pdf.Truncate(course.Title, pdf.Regular, 9, columnCredits-columnCourse-50))
+			tw.doc.TextRight(columnCredits, tw.y, pdf.Regular, 9, formatNumber(course.Credits))
+			tw.doc.Text(columnGrade, tw.y, pdf.Regular, 9, course.Letter)
+			if course.Value != course.Letter {
+				tw.doc.Text(columnScore, tw.y, pdf.Regular, 9, course.Value)
+			}
+			tw.doc.TextRight(marginRight, tw.y, pdf.Regular, 9, fmt.Sprintf("%.2f", course.Points))
+			tw.y += lineHeight
+		}
+
+		tw.need(1)
+		tw.doc.TextRight(marginRight, tw.y, pdf.Bold, 9, summaryLine("Term", term.Summary))
+		tw.y += lineHeight
+	}
+
+	tw.need(2)
+	tw.rule(1)
+	tw.doc.TextRight(marginRight, tw.y, pdf.Bold, 11, summaryLine("Cumulative", t.Cumulative))
+	return tw.doc
+}
+
+// newPage starts a page with the running title and page number.
+func (tw *transcriptWriter) newPage() {
+	tw.doc.AddPage()
+	tw.pageNum++
+	tw.y = marginTop
+	if tw.pageNum > 1 {
+		tw.doc.Text(marginLeft, tw.y-20, pdf.Regular, 8, tw.title+" (continued)")
+	}
+	tw.doc.TextRight(marginRight, pdf.PageHeight-30, pdf.Regular, 8, "Page "+strconv.Itoa(tw.pageNum))
+}
+
+// need starts a new page unless lines more lines fit on this one, reporting
+// whether it did.
+func (tw *transcriptWriter) need(lines int) bool {
+	if tw.y+float64(lines)*lineHeight <= marginBottom {
+		return false
+	}
+	tw.newPage()
+	return true
+}
+
+func (tw *transcriptWriter) header() {
+	tw.doc.Text(marginLeft, tw.y, pdf.Bold, 9, "Code")
+	tw.doc.Text(columnCourse, tw.y, pdf.Bold, 9, "Course")
+	tw.doc.TextRight(columnCredits, tw.y, pdf.Bold, 9, "Credits")
+	tw.doc.Text(columnGrade, tw.y, pdf.Bold, 9, "Grade")
+	tw.doc.Text(columnScore, tw.y, pdf.Bold, 9, "Score")
+	tw.doc.TextRight(marginRight, tw.y, pdf.Bold, 9, "Points")
+	tw.y += 4
+	tw.rule(0.5)
+}
+
+func (tw *transcriptWriter) rule(width float64) {
+	tw.doc.Line(marginLeft, tw.y, marginRight, tw.y, width)
+	tw.y += lineHeight
+}
+
+func summaryLine(label string, s grading.Summary) string {
+	return fmt.Sprintf("%s: %s credits, GPA %.2f", label, formatNumber(s.Credits), s.GPA)
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,153 @@
+package course
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/grading"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/pdf"
+)
+
+func TestWantsPDF(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		want        bool
+		wantProblem bool
+	}{
+		{name: "no preference", want: false},
+		{name: "format pdf", query: "?format=pdf", accept: "application/json", want: true},
+		{name: "unknown format", query: "?format=docx", wantProblem: true},
+		{name: "json", accept: "application/json", want: false},
+		{name: "any", accept: "*/*", want: false},
+		{name: "only pdf", accept: "application/pdf", want: true},
+		{name: "pdf with a weight", accept: "application/pdf;q=0.9", want: true},
+		{name: "pdf refused", accept: "application/pdf;q=0", want: false},
+		{name: "json and pdf", accept: "application/pdf, application/json", want: false},
+		{name: "nothing acceptable", accept: "image/png", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/students/1/transcript"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			got, problem := wantsPDF(r)
+			if (problem != nil) != tt.wantProblem {
+				t.Fatalf("wantsPDF() problem = %v, want one: %v", problem, tt.wantProblem)
+			}
+			if got != tt.want {
+				t.Fatalf("wantsPDF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	store := newRosterStore()
+	enrolled := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	store.courses[1] = []types.StudentCourse{
+		{
+			EnrollmentID: 1, Status: types.EnrollmentStatusCompleted, EnrolledAt: enrolled,
+			Course: types.Course{ID: 1, Code: "CS101", Title: "Intro", Credits: 3},
+			Grade:  &types.Grade{Term: "Fall 2025", Scale: "letter", Value: "A", Letter: "A", Points: 4},
+		},
+		{
+			EnrollmentID: 2, Status: types.EnrollmentStatusCompleted, EnrolledAt: enrolled,
+			Course: types.Course{ID: 2, Code: "MA101", Title: "Calculus", Credits: 1},
+			Grade:  &types.Grade{Term: "Fall 2025", Scale: "letter", Value: "C", Letter: "C", Points: 2},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{name: "json", accept: "application/json", status: http.StatusOK, contentType: "application/json"},
+		{name: "format pdf", query: "?format=pdf", status: http.StatusOK, contentType: pdf.ContentType},
+		{name: "accept pdf", accept: "application/pdf", status: http.StatusOK, contentType: pdf.ContentType},
+		{name: "unknown format", query: "?format=docx", status: http.StatusBadRequest, contentType: "application/problem+json"},
+		{name: "nothing acceptable", accept: "image/png", status: http.StatusNotAcceptable, contentType: "application/problem+json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/students/1/transcript"+tt.query, nil)
+			r.SetPathValue("id", "1")
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			Transcript(store)(w, as(r, "ada@example.com", types.RoleStudent))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+
+			switch tt.contentType {
+			case pdf.ContentType:
+				if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="transcript-1.pdf"` {
+					t.Errorf("Content-Disposition = %q", got)
+				}
+				if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+					t.Errorf("body starts with %.8q, want a PDF", w.Body.Bytes())
+				}
+			case "application/json":
+				var transcript grading.Transcript
+				if err := json.Unmarshal(w.Body.Bytes(), &transcript); err != nil {
+					t.Fatal(err)
+				}
+				// (3 credits * 4 + 1 credit * 2) / 4 credits
+				want := grading.Summary{Credits: 4, QualityPoints: 14, GPA: 3.5}
+				if transcript.Cumulative != want || len(transcript.Terms) != 1 {
+					t.Errorf("cumulative = %+v over %d terms, want %+v over 1", transcript.Cumulative, len(transcript.Terms), want)
+				}
+			}
+		})
+	}
+}
+
+func TestTranscriptForOtherStudent(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/students/2/transcript?format=pdf", nil)
+	r.SetPathValue("id", "2")
+	w := httptest.NewRecorder()
+	Transcript(newRosterStore())(w, as(r, "ada@example.com", types.RoleStudent))
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestSummaryLine(t *testing.T) {
+	tests := []struct {
+		label   string
+		summary grading.Summary
+		want    string
+	}{
+		{label: "Fall 2025", summary: grading.Summary{Credits: 4, GPA: 3.5}, want: "Fall 2025: 4 credits, GPA 3.50"},
+		{label: "Cumulative", summary: grading.Summary{Credits: 7.5, GPA: 3.266666}, want: "Cumulative: 7.5 credits, GPA 3.27"},
+		{label: "Spring 2026", summary: grading.Summary{}, want: "Spring 2026: 0 credits, GPA 0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := summaryLine(tt.label, tt.summary); got != tt.want {
+				t.Fatalf("summaryLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+const checkTimeout = 2 * time.Second
+
+// State records whether the process is draining. Once ShutDown is called
+// readiness fails so load balancers stop routing new traffic here.
+type State struct {
+	draining atomic.Bool
+}
+
+func (s *State) ShutDown() {
+	s.draining.Store(true)
+}
+
+type report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Liveness reports that the process is up and serving HTTP.
+func Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.WriteJSON(w, http.StatusOK, report{Status: "ok"})
+	}
+}
+
+// Readiness reports whether the instance can serve traffic: the database must
+// answer a ping, the schema must be migrated and shutdown must not have begun.
+func Readiness(storage storage.Storage, state *State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		defer cancel()
+
+		checks := map[string]string{
+			"shutdown":   "ok",
+			"database":   "ok",
+			"migrations": "ok",
+		}
+		ready := true
+
+		if state.draining.Load() {
+			checks["shutdown"] = "draining"
+			ready = false
+		}
+		if err := storage.Ping(ctx); err != nil {
+			checks["database"] = err.Error()
+			ready = false
+		}
+		if err := storage.CheckMigrations(ctx); err != nil {
+			checks["migrations"] = err.Error()
+			ready = false
+		}
+
+		if !ready {
+			response.WriteJSON(w, http.StatusServiceUnavailable, report{Status: "not ready", Checks: checks})
+			return
+		}
+		response.WriteJSON(w, http.StatusOK, report{Status: "ready", Checks: checks})
+	}
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+)
+
+// checkedStore answers the readiness checks; every other storage method is
+// left nil.
+type checkedStore struct {
+	storage.Storage
+	ping       error
+	migrations error
+}
+
+func (s checkedStore) Ping(ctx context.Context) error {
+	return s.ping
+}
+
+func (s checkedStore) CheckMigrations(ctx context.Context) error {
+	return s.migrations
+}
+
+func TestLiveness(t *testing.T) {
+	w := httptest.NewRecorder()
+	Liveness()(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "{\"status\":\"ok\"}\n" {
+		t.Fatalf("liveness = %d %s", w.Code, w.Body)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		store    checkedStore
+		draining bool
+		status   int
+		want     report
+	}{
+		{
+			name:   "ready",
+			status: http.StatusOK,
+			want:   report{Status: "ready", Checks: map[string]string{"shutdown": "ok", "database": "ok", "migrations": "ok"}},
+		},
+		{
+			name:     "draining",
+			draining: true,
+			status:   http.StatusServiceUnavailable,
+			want:     report{Status: "not ready", Checks: map[string]string{"shutdown": "draining", "database": "ok", "migrations": "ok"}},
+		},
+		{
+			name:   "database down",
+			store:  checkedStore{ping: errors.New("connection refused")},
+			status: http.StatusServiceUnavailable,
+			want:   report{Status: "not ready", Checks: map[string]string{"shutdown": "ok", "database": "connection refused", "migrations": "ok"}},
+		},
+		{
+			name:   "schema behind",
+			store:  checkedStore{migrations: errors.New("table courses is missing")},
+			status: http.StatusServiceUnavailable,
+			want:   report{Status: "not ready", Checks: map[string]string{"shutdown": "ok", "database": "ok", "migrations": "table courses is missing"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &State{}
+			if tt.draining {
+				state.ShutDown()
+			}
+
+			w := httptest.NewRecorder()
+			Readiness(tt.store, state)(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			var got report
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("report = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package student
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/xlsx"
+)
+
+// exportColumns are the fields written by every export format. Password
+// hashes are deliberately absent.
+var exportColumns = []string{"id", "name", "email", "age", "role", "created_at", "updated_at", "deleted_at"}
+
+// exportRow is the NDJSON representation of an exported student.
+type exportRow struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Age       int        `json:"age"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func newExportRow(student types.Student) exportRow {
+	row := exportRow{
+		ID:        student.ID,
+		Name:      student.Name,
+		Email:     student.Email,
+		Age:       student.Age,
+		Role:      student.Role,
+		CreatedAt: student.CreatedAt,
+		UpdatedAt: student.UpdatedAt,
+	}
+	if student.DeletedAt.Valid {
+		row.DeletedAt = &student.DeletedAt.Time
+	}
+	return row
+}
+
+// exporter writes students in one download format.
+type exporter interface {
+	write(types.Student) error
+	close() error
+}
+
+// Export streams students matching the list filters as a csv, ndjson or xlsx
+// download, reading them from the database through a cursor.
+func Export(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, problem := listFilter(r)
+		if problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+
+		var contentType string
+		switch format {
+		case "csv":
+			contentType = "text/csv; charset=utf-8"
+		case "ndjson":
+			contentType = "application/x-ndjson"
+		case "xlsx":
+			contentType = xlsx.ContentType
+		default:
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery,
+				fmt.Errorf("invalid format, expected csv, ndjson or xlsx"))
+			return
+		}
+
+		filename := fmt.Sprintf("students-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.Header().Set("Cache-Control", "no-store")
+
+		body := &exportWriter{ResponseWriter: w}
+		out, err := newExporter(format, body)
+		if err == nil {
+			err = storage.StreamStudents(r.Context(), filter, out.write)
+		}
+		if err == nil {
+			err = out.close()
+		}
+		if err == nil {
+			return
+		}
+
+		if !body.wrote {
+			w.Header().Del("Content-Disposition")
+			response.WriteStorageError(w, r, err)
+			return
+		}
+		// The status line has already been sent, so the only way to tell the
+		// client the file is incomplete is to break the connection.
+		logger.FromContext(r.Context()).Error("Export failed", slog.String("format", format), slog.String("error", err.Error()))
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportWriter notes whether any of the export has been sent, after which an
+// error can no longer be reported with a problem response.
+type exportWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *exportWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func newExporter(format string, w http.ResponseWriter) (exporter, error) {
+	switch format {
+	case "ndjson":
+		return &ndjsonExporter{encoder: json.NewEncoder(w)}, nil
+	case "xlsx":
+		sheet, err := xlsx.NewWriter(w, "Students")
+		if err != nil {
+			return nil, err
+		}
+		if err := sheet.WriteRow(stringsToCells(exportColumns)...); err != nil {
+			return nil, err
+		}
+		return &xlsxExporter{sheet: sheet}, nil
+	default:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportColumns); err != nil {
+			return nil, err
+		}
+		return &csvExporter{writer: writer}, nil
+	}
+}
+
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func (e *csvExporter) write(student types.Student) error {
+	row := newExportRow(student)
+	deletedAt := ""
+	if row.DeletedAt != nil {
+		deletedAt = row.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	return e.writer.Write([]string{
+		strconv.FormatUint(uint64(row.ID), 10),
+		neutralizeFormula(row.Name),
+		neutralizeFormula(row.Email),
+		strconv.Itoa(row.Age),
+		neutralizeFormula(row.Role),
+		row.CreatedAt.UTC().Format(time.RFC3339),
+		row.UpdatedAt.UTC().Format(time.RFC3339),
+		deletedAt,
+	})
+}
+
+func (e *csvExporter) close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) write(student types.Student) error {
+	return e.encoder.Encode(newExportRow(student))
+}
+
+func (e *ndjsonExporter) close() error {
+	return nil
+}
+
+type xlsxExporter struct {
+	sheet *xlsx.Writer
+}
+
+func (e *xlsxExporter) write(student types.Student) error {
+	row := newExportRow(student)
+	var deletedAt any
+	if row.DeletedAt != nil {
+		deletedAt = row.DeletedAt.UTC()
+	}
+	return e.sheet.WriteRow(row.ID, neutralizeFormula(row.Name), neutralizeFormula(row.Email), row.Age,
+		neutralizeFormula(row.Role), row.CreatedAt.UTC(), row.UpdatedAt.UTC(), deletedAt)
+}
+
+func (e *xlsxExporter) close() error {
+	return e.sheet.Close()
+}
+
+// neutralizeFormula prefixes text that a spreadsheet would evaluate as a
+// formula with a quote, so a student named "=HYPERLINK(...)" is shown as
+// typed when the export is opened.
+func neutralizeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func stringsToCells(values []string) []any {
+	cells := make([]any, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return cells
+}
@@ -0,0 +1,159 @@
+package student
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"gorm.io/gorm"
+)
+
+func TestNeutralizeFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Ada Lovelace", want: "Ada Lovelace"},
+		{value: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{value: "+1+1", want: "'+1+1"},
+		{value: "-2", want: "'-2"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "\r=1", want: "'\r=1"},
+		{value: "Mary-Jane", want: "Mary-Jane"},
+		{value: "a=b", want: "a=b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := neutralizeFormula(tt.value); got != tt.want {
+				t.Fatalf("neutralizeFormula(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExporters(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	students := []types.Student{
+		{ID: 1, Name: "=cmd|' /C calc'!A0", Email: "ada@example.com", Age: 20, Role: "student", Password: "hash", CreatedAt: created, UpdatedAt: created},
+		{ID: 2, Name: "Grace", Email: "grace@example.com", Age: 30, Role: "admin", Password: "hash", CreatedAt: created, UpdatedAt: created,
+			DeletedAt: gorm.DeletedAt(sql.NullTime{Time: created, Valid: true})},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "csv",
+			want: "id,name,email,age,role,created_at,updated_at,deleted_at\n" +
+				"1,'=cmd|' /C calc'!A0,ada@example.com,20,student,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z,\n" +
+				"2,Grace,grace@example.com,30,admin,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z\n",
+		},
+		{
+			format: "ndjson",
+			want: `{"id":1,"name":"=cmd|' /C calc'!A0","email":"ada@example.com","age":20,"role":"student","created_at":"2026-01-02T03:04:05Z","updated_at":"2026-01-02T03:04:05Z","deleted_at":null}` + "\n" +
+				`{"id":2,"name":"Grace","email":"grace@example.com","age":30,"role":"admin","created_at":"2026-01-02T03:04:05Z","updated_at":"2026-01-02T03:04:05Z","deleted_at":"2026-01-02T03:04:05Z"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			e, err := newExporter(tt.format, w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, student := range students {
+				if err := e.write(student); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := e.close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := w.Body.String(); got != tt.want {
+				t.Fatalf("export =\n%s\nwant\n%s", got, tt.want)
+			}
+			if strings.Contains(w.Body.String(), "hash") {
+				t.Fatal("export contains a password hash")
+			}
+		})
+	}
+}
+
+// failingStream streams rows students and then fails; every other storage
+// method is left nil.
+type failingStream struct {
+	storage.Storage
+	rows int
+}
+
+func (s failingStream) StreamStudents(ctx context.Context, filter types.StudentFilter, each func(types.Student) error) error {
+	for i := range s.rows {
+		if err := each(types.Student{ID: uint(i + 1), Name: "Ada", Email: "ada@example.com", Age: 20}); err != nil {
+			return err
+		}
+	}
+	return errors.New("connection reset by peer")
+}
+
+func TestExportFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		rows    int
+		aborted bool
+	}{
+		{name: "before any row", format: "csv"},
+		{name: "before any row as xlsx", format: "xlsx"},
+		{name: "rows still buffered", format: "csv", rows: 2},
+		{name: "rows already sent", format: "ndjson", rows: 2, aborted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/students/export?format="+tt.format, nil)
+
+			aborted := func() (aborted bool) {
+				defer func() {
+					if v := recover(); v != nil {
+						if v != http.ErrAbortHandler {
+							panic(v)
+						}
+						aborted = true
+					}
+				}()
+				Export(failingStream{rows: tt.rows})(w, r)
+				return false
+			}()
+
+			if aborted != tt.aborted {
+				t.Fatalf("aborted = %v, want %v", aborted, tt.aborted)
+			}
+			if tt.aborted {
+				return
+			}
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500", w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+			if got := w.Header().Get("Content-Disposition"); got != "" {
+				t.Errorf("Content-Disposition = %q, want none on an error", got)
+			}
+		})
+	}
+}
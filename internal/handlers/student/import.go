@@ -0,0 +1,448 @@
+package student
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Saidurbu/go-lang-crud/internal/audit"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/request"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/validation"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// importBatchSize is how many rows a best-effort import inserts per transaction.
+const importBatchSize = 100
+
+const (
+	importModeAtomic     = "atomic"
+	importModeBestEffort = "best_effort"
+)
+
+// importColumns are the CSV header names an import accepts, all required.
+var importColumns = []string{"name", "email", "password", "age"}
+
+type importRow struct {
+	line    int
+	student types.Student
+}
+
+// importError explains why the row on Line was not imported. Line counts the
+// CSV header or the first NDJSON line as line 1.
+type importError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// importReport is the result of an import. In a dry run Created counts the
+// rows that would have been created and IDs is empty.
+type importReport struct {
+	DryRun  bool          `json:"dry_run"`
+	Mode    string        `json:"mode"`
+	Total   int           `json:"total"`
+	Created int           `json:"created"`
+	Failed  int           `json:"failed"`
+	IDs     []uint        `json:"ids"`
+	Errors  []importError `json:"errors"`
+}
+
+// Import creates students from a CSV (text/csv) or NDJSON
+// (application/x-ndjson) body. In the default atomic mode nothing is created
+// unless every row is valid; mode=best_effort creates every valid row in
+// batched transactions and reports the rest. dry_run=true validates and
+// checks for existing emails without creating anything.
+func Import(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		mode := query.Get("mode")
+		if mode == "" {
+			mode = importModeAtomic
+		}
+		if mode != importModeAtomic && mode != importModeBestEffort {
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery,
+				fmt.Errorf("invalid mode, expected %s or %s", importModeAtomic, importModeBestEffort))
+			return
+		}
+
+		var dryRun bool
+		if value := query.Get("dry_run"); value != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery, fmt.Errorf("invalid dry_run"))
+				return
+			}
+		}
+
+		rows, rowErrs, problem := parseImport(r)
+		if problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if len(rows)+len(rowErrs) == 0 {
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeEmptyBody, fmt.Errorf("import contains no rows"))
+			return
+		}
+
+		trans := validation.Translator(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", trans.Locale())
+
+		report := &importReport{DryRun: dryRun, Mode: mode, Total: len(rows) + len(rowErrs), IDs: []uint{}, Errors: rowErrs}
+		if report.Errors == nil {
+			report.Errors = []importError{}
+		}
+		valid := validateImport(rows, trans, report)
+
+		if dryRun {
+			valid = checkExistingEmails(r, storage, valid, report)
+		}
+
+		if mode == importModeAtomic && len(report.Errors) > 0 {
+			writeImportReport(w, r, report)
+			return
+		}
+
+		if dryRun {
+			report.Created = len(valid)
+			writeImportReport(w, r, report)
+			return
+		}
+
+		valid = hashImport(valid, report)
+		if mode == importModeAtomic && len(report.Errors) > 0 {
+			writeImportReport(w, r, report)
+			return
+		}
+
+		var created []importRow
+		if mode == importModeAtomic {
+			ids, err := storage.CreateStudents(r.Context(), students(valid))
+			if rowErr, ok := asRowError(err); ok {
+				report.addStorageError(valid[rowErr.Index].line, rowErr.Err)
+				writeImportReport(w, r, report)
+				return
+			}
+			if err != nil {
+				response.WriteStorageError(w, r, err)
+				return
+			}
+			created = recordCreated(report, valid, ids)
+		} else {
+			for start := 0; start < len(valid); start += importBatchSize {
+				batch := valid[start:min(start+importBatchSize, len(valid))]
+				created = append(created, importBatch(r, storage, batch, report)...)
+			}
+		}
+
+		for _, row := range created {
+			audit.Record(storage, r, actorEmail(r), types.AuditActionCreate, row.student.ID, nil, &row.student)
+		}
+
+		writeImportReport(w, r, report)
+	}
+}
+
+// importBatch inserts batch in one transaction. A row the database rejects is
+// reported and the batch retried without it.
+func importBatch(r *http.Request, storage storage.Storage, batch []importRow, report *importReport) []importRow {
+	for len(batch) > 0 {
+		ids, err := storage.CreateStudents(r.Context(), students(batch))
+		if err == nil {
+			return recordCreated(report, batch, ids)
+		}
+
+		rowErr, ok := asRowError(err)
+		if !ok {
+			logger.FromContext(r.Context()).Error("Failed to import batch", slog.String("error", err.Error()))
+			for _, row := range batch {
+				report.addStorageError(row.line, err)
+			}
+			return nil
+		}
+
+		report.addStorageError(batch[rowErr.Index].line, rowErr.Err)
+		batch = append(batch[:rowErr.Index:rowErr.Index], batch[rowErr.Index+1:]...)
+	}
+	return nil
+}
+
+// hashImport hashes the passwords of rows once up front, so batches retried
+// after the database rejects a row are not hashed again. Rows that cannot be
+// hashed are reported and left out.
+func hashImport(rows []importRow, report *importReport) []importRow {
+	list := students(rows)
+	failed := make(map[int]bool)
+	if err := storage.HashPasswords(list); err != nil {
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			rowErr, _ := asRowError(err)
+			report.addStorageError(rows[rowErr.Index].line, rowErr.Err)
+			failed[rowErr.Index] = true
+		}
+	}
+
+	hashed := make([]importRow, 0, len(rows))
+	for i, row := range rows {
+		if failed[i] {
+			continue
+		}
+		row.student.Password = list[i].Password
+		hashed = append(hashed, row)
+	}
+	return hashed
+}
+
+func asRowError(err error) (*storage.RowError, bool) {
+	var rowErr *storage.RowError
+	ok := errors.As(err, &rowErr)
+	return rowErr, ok
+}
+
+func recordCreated(report *importReport, rows []importRow, ids []uint) []importRow {
+	created := make([]importRow, len(rows))
+	for i, row := range rows {
+		row.student.ID = ids[i]
+		row.student.Role = types.RoleStudent
+		created[i] = row
+		report.IDs = append(report.IDs, ids[i])
+	}
+	report.Created += len(created)
+	return created
+}
+
+func students(rows []importRow) []types.Student {
+	list := make([]types.Student, len(rows))
+	for i, row := range rows {
+		list[i] = row.student
+	}
+	return list
+}
+
+// validateImport reports rows that fail validation or repeat an earlier email
+// and returns the others.
+func validateImport(rows []importRow, trans ut.Translator, report *importReport) []importRow {
+	valid := make([]importRow, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		ok := true
+
+		if err := validation.NewStudent(row.student); err != nil {
+			var validationErrs validator.ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				report.add(importError{Line: row.line, Code: response.CodeValidationFailed, Message: err.Error()})
+				continue
+			}
+			for _, fieldErr := range validationErrs {
+				report.add(importError{Line: row.line, Field: fieldErr.Field(), Code: fieldErr.Tag(), Message: fieldErr.Translate(trans)})
+			}
+			ok = false
+		}
+
+		email := strings.ToLower(row.student.Email)
+		if first, duplicate := seen[email]; duplicate && email != "" {
+			report.add(importError{Line: row.line, Field: "Email", Code: "duplicate",
+				Message: fmt.Sprintf("email %s already appears on line %d", row.student.Email, first)})
+			ok = false
+		} else {
+			seen[email] = row.line
+		}
+
+		if ok {
+			valid = append(valid, row)
+		}
+	}
+	return valid
+}
+
+// checkExistingEmails reports rows whose email is already registered, which
+// a dry run cannot learn from inserting them.
+func checkExistingEmails(r *http.Request, storage storage.Storage, rows []importRow, report *importReport) []importRow {
+	valid := make([]importRow, 0, len(rows))
+	for _, row := range rows {
+		_, err := storage.GetStudentByEmail(r.Context(), row.student.Email)
+		switch {
+		case err == nil:
+			report.add(importError{Line: row.line, Field: "Email", Code: response.CodeConflict,
+				Message: fmt.Sprintf("email %s already registered", row.student.Email)})
+		case isNotFound(err):
+			valid = append(valid, row)
+		default:
+			report.addStorageError(row.line, err)
+		}
+	}
+	return valid
+}
+
+func (report *importReport) add(err importError) {
+	report.Errors = append(report.Errors, err)
+}
+
+func (report *importReport) addStorageError(line int, err error) {
+	problem := response.ProblemFromError(err)
+	importErr := importError{Line: line, Code: problem.Code, Message: problem.Detail}
+	if errors.Is(err, storage.ErrConflict) {
+		importErr.Field = "Email"
+	}
+	report.add(importErr)
+}
+
+func writeImportReport(w http.ResponseWriter, r *http.Request, report *importReport) {
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Line < report.Errors[j].Line })
+
+	// A row counts as failed once, however many errors it has.
+	failed := make(map[int]bool, len(report.Errors))
+	for _, err := range report.Errors {
+		failed[err.Line] = true
+	}
+	report.Failed = len(failed)
+
+	status := http.StatusOK
+	if report.Mode == importModeAtomic && len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	response.Render(w, r, status, report)
+}
+
+// parseImport reads the rows of a CSV or NDJSON body. Rows that cannot be
+// decoded are returned as errors; a body that cannot be read at all is a
+// problem.
+func parseImport(r *http.Request) ([]importRow, []importError, *response.Problem) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return parseCSV(r.Body)
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return parseNDJSON(r.Body)
+	default:
+		return nil, nil, response.NewProblem(http.StatusUnsupportedMediaType, response.CodeUnsupportedMediaType,
+			"imports must be text/csv or application/x-ndjson")
+	}
+}
+
+func parseCSV(body io.Reader) ([]importRow, []importError, *response.Problem) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, request.BodyProblem(err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet exports often start with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		index[name] = i
+	}
+	for _, column := range importColumns {
+		if _, ok := index[column]; !ok {
+			return nil, nil, response.NewProblem(http.StatusBadRequest, response.CodeInvalidBody,
+				fmt.Sprintf("csv header must contain the columns %s", strings.Join(importColumns, ", ")))
+		}
+	}
+	if len(index) != len(importColumns) {
+		return nil, nil, response.NewProblem(http.StatusBadRequest, response.CodeInvalidBody,
+			fmt.Sprintf("csv header must contain only the columns %s", strings.Join(importColumns, ", ")))
+	}
+
+	var (
+		rows []importRow
+		errs []importError
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			errs = append(errs, importError{Line: parseErr.StartLine, Code: response.CodeInvalidBody,
+				Message: fmt.Sprintf("expected %d fields, got %d", len(header), len(record))})
+			continue
+		}
+		if err != nil {
+			return nil, nil, request.BodyProblem(err)
+		}
+
+		age, err := strconv.Atoi(strings.TrimSpace(record[index["age"]]))
+		if err != nil {
+			errs = append(errs, importError{Line: line, Field: "Age", Code: "number", Message: "Age must be a whole number"})
+			continue
+		}
+
+		rows = append(rows, importRow{line: line, student: types.Student{
+			Name:     strings.TrimSpace(record[index["name"]]),
+			Email:    strings.TrimSpace(record[index["email"]]),
+			Password: record[index["password"]],
+			Age:      age,
+		}})
+	}
+	return rows, errs, nil
+}
+
+func parseNDJSON(body io.Reader) ([]importRow, []importError, *response.Problem) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var (
+		rows []importRow
+		errs []importError
+	)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var input struct {
+			Name     string `json:"name"`
+			Email    string `json:"email"`
+			Password string `json:"password"`
+			Age      int    `json:"age"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&input); err != nil || decoder.More() {
+			message := "line must contain a single JSON object"
+			if err != nil {
+				message = err.Error()
+			}
+			errs = append(errs, importError{Line: line, Code: response.CodeInvalidBody, Message: message})
+			continue
+		}
+
+		rows = append(rows, importRow{line: line, student: types.Student{
+			Name:     input.Name,
+			Email:    input.Email,
+			Password: input.Password,
+			Age:      input.Age,
+		}})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, request.BodyProblem(err)
+	}
+	return rows, errs, nil
+}
@@ -0,0 +1,124 @@
+package student
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+func TestParseImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		rows        []importRow
+		errLines    []int
+		problem     string
+	}{
+		{
+			name:        "csv",
+			contentType: "text/csv; charset=utf-8",
+			body:        "\ufeffEmail, name,password,age\nada@example.com, Ada ,pw1, 20\n",
+			rows:        []importRow{{line: 2, student: types.Student{Name: "Ada", Email: "ada@example.com", Password: "pw1", Age: 20}}},
+		},
+		{
+			name:        "csv row errors",
+			contentType: "text/csv",
+			body:        "name,email,password,age\nAda,ada@example.com,pw1\nGrace,grace@example.com,pw2,old\nLin,lin@example.com,pw3,40\n",
+			rows:        []importRow{{line: 4, student: types.Student{Name: "Lin", Email: "lin@example.com", Password: "pw3", Age: 40}}},
+			errLines:    []int{2, 3},
+		},
+		{
+			name:        "csv missing column",
+			contentType: "text/csv",
+			body:        "name,email,age\nAda,ada@example.com,20\n",
+			problem:     response.CodeInvalidBody,
+		},
+		{
+			name:        "csv extra column",
+			contentType: "text/csv",
+			body:        "name,email,password,age,role\n",
+			problem:     response.CodeInvalidBody,
+		},
+		{
+			name:        "csv empty",
+			contentType: "text/csv",
+			problem:     response.CodeEmptyBody,
+		},
+		{
+			name:        "ndjson",
+			contentType: "application/x-ndjson",
+			body:        "{\"name\":\"Ada\",\"email\":\"ada@example.com\",\"password\":\"pw1\",\"age\":20}\n\n{\"name\":\"Grace\",\"role\":\"admin\"}\n{\"name\":\"Lin\"} {}\n",
+			rows:        []importRow{{line: 1, student: types.Student{Name: "Ada", Email: "ada@example.com", Password: "pw1", Age: 20}}},
+			errLines:    []int{3, 4},
+		},
+		{
+			name:        "unsupported",
+			contentType: "application/json",
+			body:        "[]",
+			problem:     response.CodeUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/students/import", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			rows, errs, problem := parseImport(r)
+			if tt.problem != "" {
+				if problem == nil || problem.Code != tt.problem {
+					t.Fatalf("parseImport() problem = %v, want %s", problem, tt.problem)
+				}
+				return
+			}
+			if problem != nil {
+				t.Fatalf("parseImport() problem = %v", problem)
+			}
+
+			if len(rows) != len(tt.rows) {
+				t.Fatalf("rows = %+v, want %+v", rows, tt.rows)
+			}
+			for i := range rows {
+				if rows[i] != tt.rows[i] {
+					t.Errorf("row %d = %+v, want %+v", i, rows[i], tt.rows[i])
+				}
+			}
+			if len(errs) != len(tt.errLines) {
+				t.Fatalf("errors = %+v, want lines %v", errs, tt.errLines)
+			}
+			for i, err := range errs {
+				if err.Line != tt.errLines[i] {
+					t.Errorf("error %d on line %d, want %d", i, err.Line, tt.errLines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHashImport(t *testing.T) {
+	rows := []importRow{
+		{line: 2, student: types.Student{Email: "ada@example.com", Password: "Passw0rd!"}},
+		{line: 3, student: types.Student{Email: "grace@example.com", Password: strings.Repeat("x", 73)}},
+		{line: 4, student: types.Student{Email: "lin@example.com", Password: "Passw0rd!"}},
+	}
+	report := &importReport{}
+
+	hashed := hashImport(rows, report)
+
+	if len(hashed) != 2 || hashed[0].line != 2 || hashed[1].line != 4 {
+		t.Fatalf("hashed rows = %+v, want lines 2 and 4", hashed)
+	}
+	for _, row := range hashed {
+		if row.student.Password == "Passw0rd!" {
+			t.Errorf("line %d: password was not hashed", row.line)
+		}
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Line != 3 || report.Errors[0].Code != response.CodeValidationFailed {
+		t.Fatalf("report errors = %+v, want a validation error on line 3", report.Errors)
+	}
+}
@@ -0,0 +1,170 @@
+package student
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+const (
+	minSearchLength   = 3
+	maxSearchLength   = 200
+	defaultSearchSize = 20
+)
+
+type searchHighlights struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type searchResult struct {
+	Student    types.StudentResponse `json:"student"`
+	Score      float64               `json:"score"`
+	Highlights searchHighlights      `json:"highlights"`
+}
+
+type searchPage struct {
+	Query   string         `json:"query"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	Results []searchResult `json:"results"`
+}
+
+// Search ranks students by how well their name or email matches q, accepting
+// the filters and pagination of GetList. Highlights are HTML with matched
+// words wrapped in <mark>.
+func Search(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, problem := listFilter(r)
+		if problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if filter.Limit == 0 {
+			filter.Limit = defaultSearchSize
+		}
+
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if length := utf8.RuneCountInString(query); length < minSearchLength || length > maxSearchLength {
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery,
+				fmt.Errorf("q must be %d to %d characters long", minSearchLength, maxSearchLength))
+			return
+		}
+
+		matches, err := storage.SearchStudents(r.Context(), types.StudentSearch{StudentFilter: filter, Query: query})
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
+		}
+
+		terms := searchTerms(query)
+		page := searchPage{Query: query, Limit: filter.Limit, Offset: filter.Offset, Results: make([]searchResult, len(matches))}
+		for i, match := range matches {
+			page.Results[i] = searchResult{
+				Student: newStudentResponse(match.Student),
+				Score:   match.Score,
+				Highlights: searchHighlights{
+					Name:  highlight(match.Student.Name, terms),
+					Email: highlight(match.Student.Email, terms),
+				},
+			}
+		}
+
+		response.Render(w, r, http.StatusOK, page)
+	}
+}
+
+// searchTerms splits a query into lower case words the way highlight splits
+// the text it marks.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), isNotWordRune)
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// highlight HTML-escapes text and wraps every word matching one of terms in
+// <mark>. Highlighting is done here rather than by each backend so that both
+// mark typo matches, and mark them the same way.
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	for len(text) > 0 {
+		end := strings.IndexFunc(text, isNotWordRune)
+		if end == 0 {
+			_, size := utf8.DecodeRuneInString(text)
+			b.WriteString(html.EscapeString(text[:size]))
+			text = text[size:]
+			continue
+		}
+		if end < 0 {
+			end = len(text)
+		}
+
+		word := text[:end]
+		if matchesTerm(strings.ToLower(word), terms) {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		text = text[end:]
+	}
+	return b.String()
+}
+
+// matchesTerm reports whether word contains a term, or is within a small edit
+// distance of one, allowing one typo from four letters and two from eight.
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(word, term) {
+			return true
+		}
+
+		allowed := 0
+		switch n := utf8.RuneCountInString(term); {
+		case n >= 8:
+			allowed = 2
+		case n >= 4:
+			allowed = 1
+		}
+		if allowed > 0 && editDistance(word, term) <= allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance is the optimal string alignment distance between a and b: the
+// Levenshtein distance with swapped adjacent letters counting as one edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
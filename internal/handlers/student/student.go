@@ -1,18 +1,20 @@
 package student
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/Saidurbu/go-lang-crud/internal/audit"
+	"github.com/Saidurbu/go-lang-crud/internal/metrics"
 	"github.com/Saidurbu/go-lang-crud/internal/storage"
-	"github.com/Saidurbu/go-lang-crud/internal/storage/postgres"
 	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/request"
 	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/validation"
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -22,52 +24,45 @@ var jwtKey = []byte("secret_key") // store this in env in production
 
 type Claims struct {
 	Email string `json:"email"`
+	Role  string `json:"role"`
 	jwt.RegisteredClaims
 }
 
 type contextKey string
 
-const emailContextKey = contextKey("email")
+const (
+	emailContextKey = contextKey("email")
+	roleContextKey  = contextKey("role")
+)
 
 func New(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		var student types.Student
 
-		err := json.NewDecoder(r.Body).Decode(&student)
-		if errors.Is(err, io.EOF) {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(fmt.Errorf("empty request body")))
-			return
-		}
-
-		existing, _ := storage.GetStudentByEmail(student.Email)
-
-		if existing.Email != "" {
-			response.WriteJSON(w, http.StatusConflict, map[string]string{
-				"message": "email already registered",
-			})
-			return
-		}
-
-		if err != nil {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(err))
+		if problem := request.Decode(r, &student); problem != nil {
+			response.WriteProblem(w, r, problem)
 			return
 		}
 
-		if err := validator.New().Struct(student); err != nil {
+		if err := validation.NewStudent(student); err != nil {
 			validatorErrs := err.(validator.ValidationErrors)
-			response.WriteJSON(w, http.StatusBadRequest, response.ValidationError(validatorErrs))
+			response.WriteValidationError(w, r, validatorErrs)
 			return
 
 		}
 
-		lastId, err := storage.CreateStudent(student.Name, student.Email, student.Password, student.Age)
+		lastId, err := storage.CreateStudent(r.Context(), student.Name, student.Email, student.Password, student.Age)
 
 		if err != nil {
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			response.WriteStorageError(w, r, err)
 			return
 		}
-		response.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+
+		if created, err := storage.GetStudentById(r.Context(), lastId, false); err == nil {
+			audit.Record(storage, r, actorEmail(r), types.AuditActionCreate, lastId, nil, &created)
+		}
+		response.Render(w, r, http.StatusCreated, map[string]interface{}{
 			"success": true,
 			"message": "student created",
 			"id":      lastId,
@@ -82,41 +77,30 @@ func Registration(storage storage.Storage) http.HandlerFunc {
 
 		var student types.Student
 
-		err := json.NewDecoder(r.Body).Decode(&student)
-		if errors.Is(err, io.EOF) {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(fmt.Errorf("empty request body")))
-			return
-		}
-
-		if err != nil {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(err))
-			return
-		}
-
-		existing, _ := storage.GetStudentByEmail(student.Email)
-
-		if existing.Email != "" {
-			response.WriteJSON(w, http.StatusConflict, map[string]string{
-				"message": "email already registered",
-			})
+		if problem := request.Decode(r, &student); problem != nil {
+			response.WriteProblem(w, r, problem)
 			return
 		}
 
-		if err := validator.New().Struct(student); err != nil {
+		if err := validation.NewStudent(student); err != nil {
 			validatorErrs := err.(validator.ValidationErrors)
-			response.WriteJSON(w, http.StatusBadRequest, response.ValidationError(validatorErrs))
+			response.WriteValidationError(w, r, validatorErrs)
 			return
 
 		}
 
-		user, err := storage.CreateStudent(student.Name, student.Email, student.Password, student.Age)
+		user, err := storage.CreateStudent(r.Context(), student.Name, student.Email, student.Password, student.Age)
 
 		if err != nil {
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			response.WriteStorageError(w, r, err)
 			return
 		}
 
-		response.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		if created, err := storage.GetStudentById(r.Context(), user, false); err == nil {
+			audit.Record(storage, r, created.Email, types.AuditActionCreate, user, nil, &created)
+		}
+
+		response.Render(w, r, http.StatusCreated, map[string]interface{}{
 			"success": true,
 			"message": "user registered",
 			"id":      user,
@@ -132,17 +116,26 @@ func Login(storage storage.Storage) http.HandlerFunc {
 			Email    string `json:"email"`
 			Password string `json:"password"`
 		}
-		json.NewDecoder(r.Body).Decode(&input)
+		if problem := request.Decode(r, &input); problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
 
-		user, err := storage.GetStudentByEmail(input.Email)
+		user, err := storage.GetStudentByEmail(r.Context(), input.Email)
+		if isNotFound(err) {
+			metrics.LoginAttempts.WithLabelValues("failure").Inc()
+			response.WriteError(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, fmt.Errorf("invalid email"))
+			return
+		}
 		if err != nil {
-			http.Error(w, "Invalid email", http.StatusUnauthorized)
+			response.WriteStorageError(w, r, err)
 			return
 		}
 
 		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password))
 		if err != nil {
-			http.Error(w, "Invalid password", http.StatusUnauthorized)
+			metrics.LoginAttempts.WithLabelValues("failure").Inc()
+			response.WriteError(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, fmt.Errorf("invalid password"))
 			return
 		}
 
@@ -150,6 +143,7 @@ func Login(storage storage.Storage) http.HandlerFunc {
 		expirationTime := time.Now().Add(24 * time.Hour)
 		claims := &Claims{
 			Email: user.Email,
+			Role:  user.Role,
 			RegisteredClaims: jwt.RegisteredClaims{
 				ExpiresAt: jwt.NewNumericDate(expirationTime),
 			},
@@ -157,11 +151,12 @@ func Login(storage storage.Storage) http.HandlerFunc {
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 		tokenString, err := token.SignedString(jwtKey)
 		if err != nil {
-			http.Error(w, "Could not generate token", http.StatusInternalServerError)
+			response.WriteError(w, r, http.StatusInternalServerError, response.CodeInternal, fmt.Errorf("could not generate token"))
 			return
 		}
 
-		json.NewEncoder(w).Encode(map[string]string{"token": tokenString})
+		metrics.LoginAttempts.WithLabelValues("success").Inc()
+		response.Render(w, r, http.StatusOK, map[string]string{"token": tokenString})
 
 	}
 }
@@ -171,11 +166,11 @@ func Logout(w http.ResponseWriter, r *http.Request) {
 	emailVal := ctx.Value(emailContextKey)
 	email, ok := emailVal.(string)
 	if !ok || email == "" {
-		http.Error(w, "Email not found in token", http.StatusUnauthorized)
+		response.WriteError(w, r, http.StatusUnauthorized, response.CodeUnauthorized, fmt.Errorf("email not found in token"))
 		return
 	}
 
-	response.WriteJSON(w, http.StatusOK, map[string]string{"message": "logged out"})
+	response.Render(w, r, http.StatusOK, map[string]string{"message": "logged out"})
 }
 
 func GetById(storage storage.Storage) http.HandlerFunc {
@@ -183,28 +178,48 @@ func GetById(storage storage.Storage) http.HandlerFunc {
 		id := r.PathValue("id")
 		uintId, err := strconv.ParseUint(id, 10, 64)
 		if err != nil {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(fmt.Errorf("invalid id")))
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidID, fmt.Errorf("invalid id"))
+			return
+		}
+		includeDeleted, err := includeDeletedParam(r)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidQuery, err)
+			return
+		}
+		if includeDeleted && !isAdmin(r) {
+			response.WriteError(w, r, http.StatusForbidden, response.CodeForbidden, fmt.Errorf("include_deleted requires admin role"))
 			return
 		}
-		student, err := storage.GetStudentById(uint(uintId))
+
+		student, err := storage.GetStudentById(r.Context(), uint(uintId), includeDeleted)
 		if err != nil {
-			response.WriteJSON(w, http.StatusNotFound, response.GeneralError(err))
+			response.WriteStorageError(w, r, err)
 			return
 		}
 
-		response.WriteJSON(w, http.StatusOK, student)
+		response.Render(w, r, http.StatusOK, newStudentResponse(student))
 	}
 }
 
 func GetList(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		students, err := storage.GetStudents()
+		filter, problem := listFilter(r)
+		if problem != nil {
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		students, err := storage.GetStudents(r.Context(), filter)
 		if err != nil {
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			response.WriteStorageError(w, r, err)
 			return
 		}
 
-		response.WriteJSON(w, http.StatusOK, students)
+		list := make([]types.StudentResponse, len(students))
+		for i, student := range students {
+			list[i] = newStudentResponse(student)
+		}
+		response.Render(w, r, http.StatusOK, list)
 	}
 }
 
@@ -213,83 +228,213 @@ func Update(storage storage.Storage) http.HandlerFunc {
 		id := r.PathValue("id")
 		int64, err := strconv.ParseInt(id, 10, 64)
 		if err != nil {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(fmt.Errorf("invalid id")))
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidID, fmt.Errorf("invalid id"))
 			return
 		}
 
 		var student types.Student
 
-		err = json.NewDecoder(r.Body).Decode(&student)
-		if errors.Is(err, io.EOF) {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(fmt.Errorf("empty request body")))
+		if problem := request.Decode(r, &student); problem != nil {
+			response.WriteProblem(w, r, problem)
 			return
 		}
 
+		if err := validation.Struct(student); err != nil {
+			validatorErrs := err.(validator.ValidationErrors)
+			response.WriteValidationError(w, r, validatorErrs)
+			return
+
+		}
+
+		before, err := storage.GetStudentById(r.Context(), uint(int64), false)
 		if err != nil {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(err))
+			response.WriteStorageError(w, r, err)
 			return
 		}
 
-		if err := validator.New().Struct(student); err != nil {
-			validatorErrs := err.(validator.ValidationErrors)
-			response.WriteJSON(w, http.StatusBadRequest, response.ValidationError(validatorErrs))
+		err = storage.UpdateStudent(r.Context(), uint(int64), student.Name, student.Email, student.Password, student.Age)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
 			return
+		}
 
+		if after, err := storage.GetStudentById(r.Context(), uint(int64), false); err == nil {
+			audit.Record(storage, r, actorEmail(r), types.AuditActionUpdate, uint(int64), &before, &after)
+		}
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "student updated"})
+	}
+}
+func Delete(storage storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		int64, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidID, fmt.Errorf("invalid id"))
+			return
+		}
+		before, err := storage.GetStudentById(r.Context(), uint(int64), false)
+		if err != nil {
+			response.WriteStorageError(w, r, err)
+			return
 		}
 
-		err = storage.UpdateStudent(uint(int64), student.Name, student.Email, student.Password, student.Age)
+		err = storage.DeleteStudent(r.Context(), uint(int64))
 		if err != nil {
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			response.WriteStorageError(w, r, err)
 			return
 		}
-		response.WriteJSON(w, http.StatusOK, map[string]string{"success": "student updated"})
+
+		audit.Record(storage, r, actorEmail(r), types.AuditActionDelete, uint(int64), &before, nil)
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "student deleted"})
 	}
 }
-func Delete(storage storage.Storage) http.HandlerFunc {
+
+func Restore(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := r.PathValue("id")
 		int64, err := strconv.ParseInt(id, 10, 64)
 		if err != nil {
-			response.WriteJSON(w, http.StatusBadRequest, response.GeneralError(fmt.Errorf("invalid id")))
+			response.WriteError(w, r, http.StatusBadRequest, response.CodeInvalidID, fmt.Errorf("invalid id"))
 			return
 		}
-		err = storage.DeleteStudent(uint(int64))
+		err = storage.RestoreStudent(r.Context(), uint(int64))
 		if err != nil {
-			response.WriteJSON(w, http.StatusInternalServerError, response.GeneralError(err))
+			response.WriteStorageError(w, r, err)
 			return
 		}
-		response.WriteJSON(w, http.StatusOK, map[string]string{"success": "student deleted"})
+
+		if after, err := storage.GetStudentById(r.Context(), uint(int64), false); err == nil {
+			audit.Record(storage, r, actorEmail(r), types.AuditActionRestore, uint(int64), nil, &after)
+		}
+		response.Render(w, r, http.StatusOK, map[string]string{"success": "student restored"})
 	}
 }
 
-func GetProfile(storage *postgres.Postgres) http.HandlerFunc {
+func GetProfile(storage storage.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		emailVal := ctx.Value(emailContextKey)
 		email, ok := emailVal.(string)
 		if !ok || email == "" {
-			http.Error(w, "Email not found in token", http.StatusUnauthorized)
+			response.WriteError(w, r, http.StatusUnauthorized, response.CodeUnauthorized, fmt.Errorf("email not found in token"))
 			return
 		}
 
-		student, err := storage.GetStudentByEmail(email)
+		student, err := storage.GetStudentByEmail(r.Context(), email)
 		if err != nil {
-			http.Error(w, "Student not found", http.StatusNotFound)
+			response.WriteStorageError(w, r, err)
 			return
 		}
 
-		response := types.StudentResponse{
-			ID:    student.ID,
-			Name:  student.Name,
-			Email: student.Email,
-			Age:   student.Age,
-		}
+		response.Render(w, r, http.StatusOK, newStudentResponse(student))
+	}
+}
 
-		json.NewEncoder(w).Encode(response)
+// newStudentResponse is student as rendered to clients, without its password
+// hash.
+func newStudentResponse(student types.Student) types.StudentResponse {
+	resp := types.StudentResponse{
+		ID:        student.ID,
+		Name:      student.Name,
+		Email:     student.Email,
+		Age:       student.Age,
+		Role:      student.Role,
+		CreatedAt: student.CreatedAt,
+		UpdatedAt: student.UpdatedAt,
+	}
+	if student.DeletedAt.Valid {
+		resp.DeletedAt = &student.DeletedAt.Time
 	}
+	return resp
 }
 
 func EmailContextKey() interface{} {
 	return emailContextKey
 }
+
+func RoleContextKey() interface{} {
+	return roleContextKey
+}
+
+func actorEmail(r *http.Request) string {
+	email, _ := r.Context().Value(emailContextKey).(string)
+	return email
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, storage.ErrNotFound)
+}
+
+func isAdmin(r *http.Request) bool {
+	role, _ := r.Context().Value(roleContextKey).(string)
+	return role == types.RoleAdmin
+}
+
+// listFilter reads the filters shared by the student list and export from
+// the query string.
+func listFilter(r *http.Request) (types.StudentFilter, *response.Problem) {
+	includeDeleted, err := includeDeletedParam(r)
+	if err != nil {
+		return types.StudentFilter{}, response.NewProblem(http.StatusBadRequest, response.CodeInvalidQuery, err.Error())
+	}
+	if includeDeleted && !isAdmin(r) {
+		return types.StudentFilter{}, response.NewProblem(http.StatusForbidden, response.CodeForbidden, "include_deleted requires admin role")
+	}
+
+	filter := types.StudentFilter{IncludeDeleted: includeDeleted}
+	if filter.CreatedAfter, err = timeParam(r, "created_after"); err != nil {
+		return types.StudentFilter{}, response.NewProblem(http.StatusBadRequest, response.CodeInvalidQuery, err.Error())
+	}
+	if filter.UpdatedSince, err = timeParam(r, "updated_since"); err != nil {
+		return types.StudentFilter{}, response.NewProblem(http.StatusBadRequest, response.CodeInvalidQuery, err.Error())
+	}
+	if filter.Limit, err = intParam(r, "limit", 1, maxPageSize); err != nil {
+		return types.StudentFilter{}, response.NewProblem(http.StatusBadRequest, response.CodeInvalidQuery, err.Error())
+	}
+	if filter.Offset, err = intParam(r, "offset", 0, math.MaxInt32); err != nil {
+		return types.StudentFilter{}, response.NewProblem(http.StatusBadRequest, response.CodeInvalidQuery, err.Error())
+	}
+	return filter, nil
+}
+
+// maxPageSize bounds the limit query parameter of listings.
+const maxPageSize = 100
+
+// intParam parses an optional integer query parameter within [min, max],
+// returning zero when it is absent.
+func intParam(r *http.Request, name string, min, max int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid %s, expected an integer from %d to %d", name, min, max)
+	}
+	return n, nil
+}
+
+func includeDeletedParam(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("include_deleted")
+	if value == "" {
+		return false, nil
+	}
+	includeDeleted, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid include_deleted")
+	}
+	return includeDeleted, nil
+}
+
+func timeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, expected RFC 3339 timestamp", name)
+	}
+	return t, nil
+}
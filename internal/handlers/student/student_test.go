@@ -0,0 +1,93 @@
+package student
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+func TestListFilter(t *testing.T) {
+	since := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		query  string
+		admin  bool
+		want   types.StudentFilter
+		status int
+	}{
+		{name: "no filters"},
+		{
+			name:  "created after",
+			query: "?created_after=2025-09-01T12:00:00Z",
+			want:  types.StudentFilter{CreatedAfter: since},
+		},
+		{
+			name:  "updated since with an offset",
+			query: "?updated_since=2025-09-01T14:00:00%2B02:00",
+			want:  types.StudentFilter{UpdatedSince: since},
+		},
+		{
+			name:  "fractional seconds",
+			query: "?created_after=2025-09-01T12:00:00.5Z",
+			want:  types.StudentFilter{CreatedAfter: since.Add(500 * time.Millisecond)},
+		},
+		{
+			name:  "both bounds and paging",
+			query: "?created_after=2025-09-01T12:00:00Z&updated_since=2025-09-01T12:00:00Z&limit=10&offset=5",
+			want:  types.StudentFilter{CreatedAfter: since, UpdatedSince: since, Limit: 10, Offset: 5},
+		},
+		{name: "date without a time", query: "?created_after=2025-09-01", status: http.StatusBadRequest},
+		{name: "time without a zone", query: "?updated_since=2025-09-01T12:00:00", status: http.StatusBadRequest},
+		{name: "not a timestamp", query: "?updated_since=yesterday", status: http.StatusBadRequest},
+		{name: "unix seconds", query: "?created_after=1756728000", status: http.StatusBadRequest},
+		{name: "limit out of range", query: "?limit=101", status: http.StatusBadRequest},
+		{name: "deleted students as a student", query: "?include_deleted=true", status: http.StatusForbidden},
+		{name: "deleted students as an admin", query: "?include_deleted=true", admin: true, want: types.StudentFilter{IncludeDeleted: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/students"+tt.query, nil)
+			if tt.admin {
+				r = r.WithContext(context.WithValue(r.Context(), roleContextKey, types.RoleAdmin))
+			}
+
+			got, problem := listFilter(r)
+			if problem != nil {
+				if problem.Status != tt.status {
+					t.Fatalf("listFilter() problem status = %d, want %d: %s", problem.Status, tt.status, problem.Detail)
+				}
+				return
+			}
+			if tt.status != 0 {
+				t.Fatalf("listFilter() = %+v, want status %d", got, tt.status)
+			}
+			if !got.CreatedAfter.Equal(tt.want.CreatedAfter) || !got.UpdatedSince.Equal(tt.want.UpdatedSince) ||
+				got.IncludeDeleted != tt.want.IncludeDeleted || got.Limit != tt.want.Limit || got.Offset != tt.want.Offset {
+				t.Fatalf("listFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetListRejectsMalformedTimestamps(t *testing.T) {
+	for _, query := range []string{"?created_after=2025-13-01T00:00:00Z", "?updated_since=2025-09-01%2012:00:00"} {
+		t.Run(query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			// The query is rejected before storage is touched.
+			GetList(nil)(w, httptest.NewRequest(http.MethodGet, "/api/students"+query, nil))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Fatalf("Content-Type = %q, want application/problem+json", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// courseStore answers GetCourse and GetCourses; every other storage method is
+// left nil.
+type courseStore struct {
+	storage.Storage
+	err error
+}
+
+func (s courseStore) GetCourse(ctx context.Context, id uint) (types.Course, error) {
+	return types.Course{ID: id}, s.err
+}
+
+func (s courseStore) GetCourses(ctx context.Context) ([]types.Course, error) {
+	return nil, s.err
+}
+
+func TestInstrumentStorage(t *testing.T) {
+	tests := []struct {
+		backend string
+		err     error
+		result  string
+	}{
+		{backend: "test-ok", result: "ok"},
+		{backend: "test-error", err: storage.ErrNotFound, result: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			s := InstrumentStorage(courseStore{err: tt.err}, tt.backend)
+
+			course, err := s.GetCourse(context.Background(), 3)
+			if course.ID != 3 || !errors.Is(err, tt.err) {
+				t.Fatalf("GetCourse() = %+v, %v; the wrapped result was not passed through", course, err)
+			}
+			s.GetCourse(context.Background(), 4)
+			s.GetCourses(context.Background())
+
+			for method, want := range map[string]int{"GetCourse": 2, "GetCourses": 1} {
+				if got := sampleCount(t, tt.backend, method, tt.result); got != want {
+					t.Errorf("%s observed %d times, want %d", method, got, want)
+				}
+			}
+		})
+	}
+}
+
+// sampleCount reads the number of observations of one storage histogram
+// series from the registry's exposition.
+func sampleCount(t *testing.T, backend, method, result string) int {
+	t.Helper()
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "crud_api_storage_operation_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels["backend"] == backend && labels["method"] == method && labels["result"] == result {
+				return int(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	stats := sql.DBStats{MaxOpenConnections: 10, OpenConnections: 4, InUse: 3, Idle: 1, WaitCount: 7, WaitDuration: 1500 * time.Millisecond}
+	if err := RegisterDBStats("test", func() sql.DBStats { return stats }); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDBStats("test", func() sql.DBStats { return stats }); err == nil {
+		t.Fatal("registering the same backend twice succeeded")
+	}
+
+	want := `
+# HELP crud_api_db_in_use_connections The number of connections currently in use.
+# TYPE crud_api_db_in_use_connections gauge
+crud_api_db_in_use_connections{backend="test"} 3
+# HELP crud_api_db_wait_duration_seconds_total The total time blocked waiting for a new connection.
+# TYPE crud_api_db_wait_duration_seconds_total counter
+crud_api_db_wait_duration_seconds_total{backend="test"} 1.5
+`
+	err := testutil.GatherAndCompare(Registry, strings.NewReader(want),
+		"crud_api_db_in_use_connections", "crud_api_db_wait_duration_seconds_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
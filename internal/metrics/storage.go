@@ -0,0 +1,210 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// instrumentedStorage records the latency and outcome of every call made to
+// the wrapped backend.
+type instrumentedStorage struct {
+	next    storage.Storage
+	backend string
+}
+
+// InstrumentStorage wraps s so each method reports to
+// StorageOperationDuration under the given backend label.
+func InstrumentStorage(s storage.Storage, backend string) storage.Storage {
+	return &instrumentedStorage{next: s, backend: backend}
+}
+
+// observe starts timing method; the returned func records the final error
+// through a pointer, so callers write `defer s.observe("Method")(&err)`.
+func (s *instrumentedStorage) observe(method string) func(*error) {
+	start := time.Now()
+	return func(err *error) {
+		result := "ok"
+		if *err != nil {
+			result = "error"
+		}
+		StorageOperationDuration.WithLabelValues(s.backend, method, result).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (s *instrumentedStorage) CreateStudent(ctx context.Context, name string, email string, password string, age int) (id uint, err error) {
+	defer s.observe("CreateStudent")(&err)
+	return s.next.CreateStudent(ctx, name, email, password, age)
+}
+
+func (s *instrumentedStorage) CreateStudents(ctx context.Context, students []types.Student) (ids []uint, err error) {
+	defer s.observe("CreateStudents")(&err)
+	return s.next.CreateStudents(ctx, students)
+}
+
+func (s *instrumentedStorage) GetStudentById(ctx context.Context, id uint, includeDeleted bool) (student types.Student, err error) {
+	defer s.observe("GetStudentById")(&err)
+	return s.next.GetStudentById(ctx, id, includeDeleted)
+}
+
+func (s *instrumentedStorage) GetStudents(ctx context.Context, filter types.StudentFilter) (students []types.Student, err error) {
+	defer s.observe("GetStudents")(&err)
+	return s.next.GetStudents(ctx, filter)
+}
+
+func (s *instrumentedStorage) StreamStudents(ctx context.Context, filter types.StudentFilter, each func(types.Student) error) (err error) {
+	defer s.observe("StreamStudents")(&err)
+	return s.next.StreamStudents(ctx, filter, each)
+}
+
+func (s *instrumentedStorage) SearchStudents(ctx context.Context, search types.StudentSearch) (matches []types.StudentMatch, err error) {
+	defer s.observe("SearchStudents")(&err)
+	return s.next.SearchStudents(ctx, search)
+}
+
+func (s *instrumentedStorage) UpdateStudent(ctx context.Context, id uint, name string, email string, password string, age int) (err error) {
+	defer s.observe("UpdateStudent")(&err)
+	return s.next.UpdateStudent(ctx, id, name, email, password, age)
+}
+
+func (s *instrumentedStorage) DeleteStudent(ctx context.Context, id uint) (err error) {
+	defer s.observe("DeleteStudent")(&err)
+	return s.next.DeleteStudent(ctx, id)
+}
+
+func (s *instrumentedStorage) RestoreStudent(ctx context.Context, id uint) (err error) {
+	defer s.observe("RestoreStudent")(&err)
+	return s.next.RestoreStudent(ctx, id)
+}
+
+func (s *instrumentedStorage) PurgeDeletedStudents(ctx context.Context, before time.Time) (purged int64, err error) {
+	defer s.observe("PurgeDeletedStudents")(&err)
+	return s.next.PurgeDeletedStudents(ctx, before)
+}
+
+func (s *instrumentedStorage) GetStudentByEmail(ctx context.Context, email string) (student types.Student, err error) {
+	defer s.observe("GetStudentByEmail")(&err)
+	return s.next.GetStudentByEmail(ctx, email)
+}
+
+func (s *instrumentedStorage) CreateCourse(ctx context.Context, course types.Course) (id uint, err error) {
+	defer s.observe("CreateCourse")(&err)
+	return s.next.CreateCourse(ctx, course)
+}
+
+func (s *instrumentedStorage) GetCourse(ctx context.Context, id uint) (course types.Course, err error) {
+	defer s.observe("GetCourse")(&err)
+	return s.next.GetCourse(ctx, id)
+}
+
+func (s *instrumentedStorage) GetCourses(ctx context.Context) (courses []types.Course, err error) {
+	defer s.observe("GetCourses")(&err)
+	return s.next.GetCourses(ctx)
+}
+
+func (s *instrumentedStorage) UpdateCourse(ctx context.Context, course types.Course) (err error) {
+	defer s.observe("UpdateCourse")(&err)
+	return s.next.UpdateCourse(ctx, course)
+}
+
+func (s *instrumentedStorage) DeleteCourse(ctx context.Context, id uint) (err error) {
+	defer s.observe("DeleteCourse")(&err)
+	return s.next.DeleteCourse(ctx, id)
+}
+
+func (s *instrumentedStorage) CreateEnrollment(ctx context.Context, studentID uint, courseID uint) (id uint, err error) {
+	defer s.observe("CreateEnrollment")(&err)
+	return s.next.CreateEnrollment(ctx, studentID, courseID)
+}
+
+func (s *instrumentedStorage) GetEnrollment(ctx context.Context, id uint) (enrollment types.Enrollment, err error) {
+	defer s.observe("GetEnrollment")(&err)
+	return s.next.GetEnrollment(ctx, id)
+}
+
+func (s *instrumentedStorage) GetEnrollments(ctx context.Context, filter types.EnrollmentFilter) (enrollments []types.Enrollment, err error) {
+	defer s.observe("GetEnrollments")(&err)
+	return s.next.GetEnrollments(ctx, filter)
+}
+
+func (s *instrumentedStorage) UpdateEnrollmentStatus(ctx context.Context, id uint, status string) (err error) {
+	defer s.observe("UpdateEnrollmentStatus")(&err)
+	return s.next.UpdateEnrollmentStatus(ctx, id, status)
+}
+
+func (s *instrumentedStorage) DeleteEnrollment(ctx context.Context, id uint) (err error) {
+	defer s.observe("DeleteEnrollment")(&err)
+	return s.next.DeleteEnrollment(ctx, id)
+}
+
+func (s *instrumentedStorage) GetStudentCourses(ctx context.Context, studentID uint) (courses []types.StudentCourse, err error) {
+	defer s.observe("GetStudentCourses")(&err)
+	return s.next.GetStudentCourses(ctx, studentID)
+}
+
+func (s *instrumentedStorage) SetGrade(ctx context.Context, grade types.Grade) (saved types.Grade, err error) {
+	defer s.observe("SetGrade")(&err)
+	return s.next.SetGrade(ctx, grade)
+}
+
+func (s *instrumentedStorage) GetGrade(ctx context.Context, enrollmentID uint) (grade types.Grade, err error) {
+	defer s.observe("GetGrade")(&err)
+	return s.next.GetGrade(ctx, enrollmentID)
+}
+
+func (s *instrumentedStorage) DeleteGrade(ctx context.Context, enrollmentID uint) (err error) {
+	defer s.observe("DeleteGrade")(&err)
+	return s.next.DeleteGrade(ctx, enrollmentID)
+}
+
+func (s *instrumentedStorage) CreateAuditEntry(ctx context.Context, entry types.AuditEntry) (err error) {
+	defer s.observe("CreateAuditEntry")(&err)
+	return s.next.CreateAuditEntry(ctx, entry)
+}
+
+func (s *instrumentedStorage) GetAuditEntries(ctx context.Context, filter types.AuditFilter) (entries []types.AuditEntry, err error) {
+	defer s.observe("GetAuditEntries")(&err)
+	return s.next.GetAuditEntries(ctx, filter)
+}
+
+func (s *instrumentedStorage) CreateIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) (err error) {
+	defer s.observe("CreateIdempotencyRecord")(&err)
+	return s.next.CreateIdempotencyRecord(ctx, record)
+}
+
+func (s *instrumentedStorage) GetIdempotencyRecord(ctx context.Context, owner string, key string) (record types.IdempotencyRecord, err error) {
+	defer s.observe("GetIdempotencyRecord")(&err)
+	return s.next.GetIdempotencyRecord(ctx, owner, key)
+}
+
+func (s *instrumentedStorage) CompleteIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) (err error) {
+	defer s.observe("CompleteIdempotencyRecord")(&err)
+	return s.next.CompleteIdempotencyRecord(ctx, record)
+}
+
+func (s *instrumentedStorage) DeleteIdempotencyRecord(ctx context.Context, owner string, key string) (err error) {
+	defer s.observe("DeleteIdempotencyRecord")(&err)
+	return s.next.DeleteIdempotencyRecord(ctx, owner, key)
+}
+
+func (s *instrumentedStorage) PurgeExpiredIdempotencyRecords(ctx context.Context, before time.Time) (purged int64, err error) {
+	defer s.observe("PurgeExpiredIdempotencyRecords")(&err)
+	return s.next.PurgeExpiredIdempotencyRecords(ctx, before)
+}
+
+func (s *instrumentedStorage) DBStats() sql.DBStats {
+	return s.next.DBStats()
+}
+
+func (s *instrumentedStorage) Ping(ctx context.Context) (err error) {
+	defer s.observe("Ping")(&err)
+	return s.next.Ping(ctx)
+}
+
+func (s *instrumentedStorage) CheckMigrations(ctx context.Context) (err error) {
+	defer s.observe("CheckMigrations")(&err)
+	return s.next.CheckMigrations(ctx)
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+type clientCertKey struct{}
+
+// ClientCertAuth maps a verified mutual-TLS client certificate to an identity
+// that JWTAuth accepts in place of a bearer token. The identity is the first
+// email SAN, or the subject common name; identities listed in admins get the
+// admin role and everyone else the student role.
+func ClientCertAuth(admins []string) func(http.Handler) http.Handler {
+	isAdmin := make(map[string]bool, len(admins))
+	for _, admin := range admins {
+		isAdmin[admin] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity := certIdentity(r.TLS.VerifiedChains[0][0])
+			if identity == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			role := types.RoleStudent
+			if isAdmin[identity] {
+				role = types.RoleAdmin
+			}
+
+			ctx := context.WithValue(r.Context(), clientCertKey{}, &Claims{Email: identity, Role: role})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func certIdentity(cert *x509.Certificate) string {
+	if len(cert.EmailAddresses) > 0 {
+		return cert.EmailAddresses[0]
+	}
+	return cert.Subject.CommonName
+}
+
+func clientCertIdentityFrom(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(clientCertKey{}).(*Claims)
+	return claims, ok
+}
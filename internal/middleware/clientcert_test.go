@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+)
+
+func TestClientCertAuth(t *testing.T) {
+	verified := func(cert *x509.Certificate) *tls.ConnectionState {
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+
+	tests := []struct {
+		name   string
+		tls    *tls.ConnectionState
+		auth   string
+		status int
+		email  string
+		role   string
+	}{
+		{name: "plain http", status: http.StatusUnauthorized},
+		{name: "unverified certificate", tls: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "ops"}}}}, status: http.StatusUnauthorized},
+		{
+			name:   "email SAN",
+			tls:    verified(&x509.Certificate{Subject: pkix.Name{CommonName: "ignored"}, EmailAddresses: []string{"ada@example.com", "other@example.com"}}),
+			status: http.StatusOK, email: "ada@example.com", role: "student",
+		},
+		{
+			name:   "common name of an admin",
+			tls:    verified(&x509.Certificate{Subject: pkix.Name{CommonName: "ops-robot"}}),
+			status: http.StatusOK, email: "ops-robot", role: "admin",
+		},
+		{
+			name:   "no identity",
+			tls:    verified(&x509.Certificate{}),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "bearer token wins",
+			tls:    verified(&x509.Certificate{Subject: pkix.Name{CommonName: "ops-robot"}}),
+			auth:   bearer(t, "grace@example.com"),
+			status: http.StatusOK, email: "grace@example.com",
+		},
+		{
+			name:   "invalid token is not rescued by the certificate",
+			tls:    verified(&x509.Certificate{Subject: pkix.Name{CommonName: "ops-robot"}}),
+			auth:   "Bearer forged",
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var email, role string
+			h := ClientCertAuth([]string{"ops-robot"})(JWTAuth(func(w http.ResponseWriter, r *http.Request) {
+				email, _ = r.Context().Value(student.EmailContextKey()).(string)
+				role, _ = r.Context().Value(student.RoleContextKey()).(string)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+			r.TLS = tt.tls
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if email != tt.email || role != tt.role {
+				t.Fatalf("identity = %q (%q), want %q (%q)", email, role, tt.email, tt.role)
+			}
+		})
+	}
+}
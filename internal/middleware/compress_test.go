@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"zstd", "br", "gzip"}
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{acceptEncoding: "", want: ""},
+		{acceptEncoding: "identity", want: ""},
+		{acceptEncoding: "gzip", want: "gzip"},
+		{acceptEncoding: "gzip, br", want: "br"},
+		{acceptEncoding: "gzip, deflate, br, zstd", want: "zstd"},
+		{acceptEncoding: "zstd;q=0.5, gzip", want: "gzip"},
+		{acceptEncoding: "GZIP;q=0.8, br;q=0.9", want: "br"},
+		{acceptEncoding: "*", want: "zstd"},
+		{acceptEncoding: "*, zstd;q=0", want: "br"},
+		{acceptEncoding: "gzip;q=0", want: ""},
+		{acceptEncoding: "gzip;q=bad, br;q=0.1", want: "br"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			if got := negotiateEncoding(tt.acceptEncoding, supported); got != tt.want {
+				t.Fatalf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"name":"Ada Lovelace"},`, 100)
+
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		contentType    string
+		status         int
+		body           string
+		wantEncoding   string
+	}{
+		{name: "gzip", acceptEncoding: "gzip", contentType: "application/json", body: large, wantEncoding: "gzip"},
+		{name: "brotli", acceptEncoding: "br", contentType: "application/json", body: large, wantEncoding: "br"},
+		{name: "zstd", acceptEncoding: "zstd", contentType: "application/json; charset=utf-8", body: large, wantEncoding: "zstd"},
+		{name: "not accepted", contentType: "application/json", body: large},
+		{name: "below min size", acceptEncoding: "gzip", contentType: "application/json", body: `{"id":1}`},
+		{name: "content type not allowed", acceptEncoding: "gzip", contentType: "image/png", body: large},
+		{name: "no content", acceptEncoding: "gzip", contentType: "application/json", status: http.StatusNoContent},
+		{name: "head", method: http.MethodHead, acceptEncoding: "gzip", contentType: "application/json"},
+	}
+
+	handler := Compress(config.Compression{
+		Enabled:      true,
+		MinSize:      256,
+		Encodings:    []string{"zstd", "br", "gzip"},
+		ContentTypes: []string{"application/json"},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			h := handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(status)
+				// Write in two pieces so buffering across writes is exercised.
+				half := len(tt.body) / 2
+				io.WriteString(w, tt.body[:half])
+				io.WriteString(w, tt.body[half:])
+			}))
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "/api/students", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != status {
+				t.Fatalf("status = %d, want %d", w.Code, status)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if method != http.MethodHead && w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", w.Header().Get("Vary"))
+			}
+
+			if got := decode(t, tt.wantEncoding, w.Body.Bytes()); got != tt.body {
+				t.Fatalf("body = %.60q, want %.60q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestCompressFlushBeforeWrite(t *testing.T) {
+	handler := Compress(config.Compression{
+		Enabled:      true,
+		MinSize:      1024,
+		Encodings:    []string{"gzip"},
+		ContentTypes: []string{"application/x-ndjson"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		http.NewResponseController(w).Flush()
+		io.WriteString(w, "{}\n")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/api/students/export", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if !w.Flushed {
+		t.Fatal("response was not flushed")
+	}
+	if got := w.Body.String(); got != "{}\n" {
+		t.Fatalf("body = %q, want it sent uncompressed", got)
+	}
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var r io.Reader
+	switch encoding {
+	case "":
+		return string(body)
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", encoding, err)
+	}
+	return string(decoded)
+}
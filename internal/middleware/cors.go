@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+)
+
+// CORS adds cross-origin headers for allowed origins and answers preflight
+// requests for any method and path the router has a route for. Preflights for
+// unknown routes fall through to the router's 404 or 405.
+func CORS(cfg config.CORS, router *http.ServeMux) func(http.Handler) http.Handler {
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+
+	allowed := func(origin string) bool {
+		return anyOrigin || slices.Contains(cfg.AllowedOrigins, origin)
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(cfg.AllowedOrigins) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			header := w.Header()
+			header.Add("Vary", "Origin")
+
+			if origin == "" || !allowed(origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// A wildcard cannot be combined with credentials, so the
+			// origin is echoed back instead.
+			if anyOrigin && !cfg.AllowCredentials {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			requestedMethod := r.Header.Get("Access-Control-Request-Method")
+			if r.Method != http.MethodOptions || requestedMethod == "" {
+				if exposeHeaders != "" {
+					header.Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !slices.Contains(cfg.AllowedMethods, requestedMethod) || !hasRoute(router, r, requestedMethod) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+			header.Set("Access-Control-Max-Age", maxAge)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// hasRoute reports whether router would route r if it used method.
+func hasRoute(router *http.ServeMux, r *http.Request, method string) bool {
+	probe := r.Clone(r.Context())
+	probe.Method = method
+	_, pattern := router.Handler(probe)
+	return pattern != ""
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+)
+
+func TestCORS(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/students", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("DELETE /api/students/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	base := config.CORS{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST", "DELETE"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		ExposedHeaders: []string{"X-Request-ID"},
+		MaxAge:         10 * time.Minute,
+	}
+	wildcard := base
+	wildcard.AllowedOrigins = []string{"*"}
+	credentials := wildcard
+	credentials.AllowCredentials = true
+
+	tests := []struct {
+		name            string
+		cfg             config.CORS
+		method          string
+		path            string
+		origin          string
+		requestMethod   string
+		status          int
+		allowOrigin     string
+		allowMethods    string
+		exposeHeaders   string
+		allowCredential string
+	}{
+		{
+			name: "same origin", cfg: base, method: http.MethodGet, path: "/api/students",
+			status: http.StatusOK,
+		},
+		{
+			name: "allowed origin", cfg: base, method: http.MethodGet, path: "/api/students",
+			origin: "https://app.example.com", status: http.StatusOK,
+			allowOrigin: "https://app.example.com", exposeHeaders: "X-Request-ID",
+		},
+		{
+			name: "unknown origin", cfg: base, method: http.MethodGet, path: "/api/students",
+			origin: "https://evil.example.com", status: http.StatusOK,
+		},
+		{
+			name: "preflight", cfg: base, method: http.MethodOptions, path: "/api/students/7",
+			origin: "https://app.example.com", requestMethod: "DELETE", status: http.StatusNoContent,
+			allowOrigin: "https://app.example.com", allowMethods: "GET, POST, DELETE",
+		},
+		{
+			name: "preflight for a method without a route", cfg: base, method: http.MethodOptions, path: "/api/students/7",
+			origin: "https://app.example.com", requestMethod: "POST", status: http.StatusMethodNotAllowed,
+			allowOrigin: "https://app.example.com",
+		},
+		{
+			name: "preflight for an unknown path", cfg: base, method: http.MethodOptions, path: "/api/nothing",
+			origin: "https://app.example.com", requestMethod: "GET", status: http.StatusNotFound,
+			allowOrigin: "https://app.example.com",
+		},
+		{
+			name: "wildcard", cfg: wildcard, method: http.MethodGet, path: "/api/students",
+			origin: "https://other.example.com", status: http.StatusOK,
+			allowOrigin: "*", exposeHeaders: "X-Request-ID",
+		},
+		{
+			name: "wildcard with credentials echoes the origin", cfg: credentials, method: http.MethodGet, path: "/api/students",
+			origin: "https://other.example.com", status: http.StatusOK,
+			allowOrigin: "https://other.example.com", exposeHeaders: "X-Request-ID", allowCredential: "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.requestMethod != "" {
+				r.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			w := httptest.NewRecorder()
+			CORS(tt.cfg, router)(router).ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			for name, want := range map[string]string{
+				"Access-Control-Allow-Origin":      tt.allowOrigin,
+				"Access-Control-Allow-Methods":     tt.allowMethods,
+				"Access-Control-Expose-Headers":    tt.exposeHeaders,
+				"Access-Control-Allow-Credentials": tt.allowCredential,
+			} {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if got := w.Header().Get("Vary"); got != "Origin" && tt.requestMethod == "" {
+				t.Errorf("Vary = %q, want Origin", got)
+			}
+		})
+	}
+}
+
+func TestCORSDisabledWithoutOrigins(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/students", func(w http.ResponseWriter, r *http.Request) {})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	CORS(config.CORS{}, router)(router).ServeHTTP(w, r)
+
+	if got := w.Header().Get("Vary"); got != "" {
+		t.Fatalf("Vary = %q, want CORS left off entirely", got)
+	}
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/request"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency makes a handler safe to retry when the client sends an
+// Idempotency-Key header. The first request with a key runs normally and its
+// response is stored for ttl; retries with the same body get that response
+// replayed, while reusing the key with a different body is rejected with 422.
+// Behind JWTAuth keys are scoped to the caller; unauthenticated requests share
+// one scope, where the body fingerprint still keeps a key from replaying a
+// response to anyone who did not send the same request.
+func Idempotency(store storage.Storage, ttl time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				response.WriteError(w, r, http.StatusBadRequest, response.CodeBadRequest,
+					fmt.Errorf("%s must not exceed %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				response.WriteProblem(w, r, request.BodyProblem(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			owner, _ := r.Context().Value(student.EmailContextKey()).(string)
+			fingerprint := requestFingerprint(r, body)
+
+			existing, err := store.GetIdempotencyRecord(r.Context(), owner, key)
+			switch {
+			case err == nil:
+				replayIdempotent(w, r, existing, fingerprint)
+				return
+			case !errors.Is(err, storage.ErrNotFound):
+				response.WriteStorageError(w, r, err)
+				return
+			}
+
+			now := time.Now().UTC()
+			record := types.IdempotencyRecord{
+				Owner:       owner,
+				Key:         key,
+				Fingerprint: fingerprint,
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(ttl),
+			}
+			if err := store.CreateIdempotencyRecord(r.Context(), record); err != nil {
+				if errors.Is(err, storage.ErrConflict) {
+					// Another request with this key won the race to reserve it.
+					response.WriteError(w, r, http.StatusConflict, response.CodeConflict,
+						errors.New("a request with this idempotency key is already being processed"))
+					return
+				}
+				response.WriteStorageError(w, r, err)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next(rec, r)
+
+			// The record outlives the request, so it is saved even if the
+			// client has already gone away.
+			ctx := context.WithoutCancel(r.Context())
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			if rec.status >= http.StatusInternalServerError {
+				// Server errors are not final; let the client retry the key.
+				if err := store.DeleteIdempotencyRecord(ctx, owner, key); err != nil {
+					logger.FromContext(ctx).Error("Failed to release idempotency key", slog.String("error", err.Error()))
+				}
+				return
+			}
+
+			record.StatusCode = rec.status
+			record.ContentType = rec.Header().Get("Content-Type")
+			record.Body = rec.body.Bytes()
+			if err := store.CompleteIdempotencyRecord(ctx, record); err != nil {
+				logger.FromContext(ctx).Error("Failed to store idempotent response", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func replayIdempotent(w http.ResponseWriter, r *http.Request, record types.IdempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		response.WriteError(w, r, http.StatusUnprocessableEntity, response.CodeIdempotencyKeyReused,
+			errors.New("idempotency key was already used with a different request"))
+		return
+	}
+	if record.StatusCode == 0 {
+		response.WriteError(w, r, http.StatusConflict, response.CodeConflict,
+			errors.New("a request with this idempotency key is already being processed"))
+		return
+	}
+
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// requestFingerprint identifies a request by method, path and body, so a key
+// reused for anything else can be told apart from a genuine retry.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// idempotencyStore keeps idempotency records in memory. Every other storage
+// method is left nil and panics if called.
+type idempotencyStore struct {
+	storage.Storage
+
+	mu      sync.Mutex
+	records map[string]types.IdempotencyRecord
+}
+
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{records: make(map[string]types.IdempotencyRecord)}
+}
+
+func (s *idempotencyStore) CreateIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := record.Owner + "\x00" + record.Key
+	if _, ok := s.records[id]; ok {
+		return fmt.Errorf("idempotency key %q: %w", record.Key, storage.ErrConflict)
+	}
+	s.records[id] = record
+	return nil
+}
+
+func (s *idempotencyStore) GetIdempotencyRecord(ctx context.Context, owner string, key string) (types.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[owner+"\x00"+key]
+	if !ok {
+		return types.IdempotencyRecord{}, fmt.Errorf("idempotency key %q: %w", key, storage.ErrNotFound)
+	}
+	return record, nil
+}
+
+func (s *idempotencyStore) CompleteIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Owner+"\x00"+record.Key] = record
+	return nil
+}
+
+func (s *idempotencyStore) DeleteIdempotencyRecord(ctx context.Context, owner string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, owner+"\x00"+key)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	type request struct {
+		owner    string
+		key      string
+		path     string
+		body     string
+		status   int
+		replayed bool
+	}
+
+	tests := []struct {
+		name     string
+		status   int
+		requests []request
+		calls    int
+	}{
+		{
+			name:   "no key runs every time",
+			status: http.StatusCreated,
+			requests: []request{
+				{body: `{"name":"Ada"}`, status: http.StatusCreated},
+				{body: `{"name":"Ada"}`, status: http.StatusCreated},
+			},
+			calls: 2,
+		},
+		{
+			name:   "retry is replayed",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "k1", body: `{"name":"Ada"}`, status: http.StatusCreated},
+				{key: "k1", body: `{"name":"Ada"}`, status: http.StatusCreated, replayed: true},
+				{key: "k1", body: `{"name":"Ada"}`, status: http.StatusCreated, replayed: true},
+			},
+			calls: 1,
+		},
+		{
+			name:   "client errors are replayed too",
+			status: http.StatusBadRequest,
+			requests: []request{
+				{key: "k1", body: `{}`, status: http.StatusBadRequest},
+				{key: "k1", body: `{}`, status: http.StatusBadRequest, replayed: true},
+			},
+			calls: 1,
+		},
+		{
+			name:   "different body is rejected",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "k1", body: `{"name":"Ada"}`, status: http.StatusCreated},
+				{key: "k1", body: `{"name":"Grace"}`, status: http.StatusUnprocessableEntity},
+			},
+			calls: 1,
+		},
+		{
+			name:   "different path is rejected",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "k1", body: `{}`, status: http.StatusCreated},
+				{key: "k1", path: "/api/enrollments", body: `{}`, status: http.StatusUnprocessableEntity},
+			},
+			calls: 1,
+		},
+		{
+			name:   "different query is rejected",
+			status: http.StatusOK,
+			requests: []request{
+				{key: "k1", path: "/api/students/import?dry_run=true", body: `{}`, status: http.StatusOK},
+				{key: "k1", path: "/api/students/import?dry_run=true", body: `{}`, status: http.StatusOK, replayed: true},
+				{key: "k1", path: "/api/students/import", body: `{}`, status: http.StatusUnprocessableEntity},
+			},
+			calls: 1,
+		},
+		{
+			name:   "keys are scoped to the caller",
+			status: http.StatusCreated,
+			requests: []request{
+				{owner: "ada@example.com", key: "k1", body: `{}`, status: http.StatusCreated},
+				{owner: "grace@example.com", key: "k1", body: `{}`, status: http.StatusCreated},
+				{owner: "ada@example.com", key: "k1", body: `{}`, status: http.StatusCreated, replayed: true},
+			},
+			calls: 2,
+		},
+		{
+			name:   "server errors release the key",
+			status: http.StatusInternalServerError,
+			requests: []request{
+				{key: "k1", body: `{}`, status: http.StatusInternalServerError},
+				{key: "k1", body: `{}`, status: http.StatusInternalServerError},
+			},
+			calls: 2,
+		},
+		{
+			name:   "overlong key",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: strings.Repeat("k", maxIdempotencyKeyLength+1), body: `{}`, status: http.StatusBadRequest},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			h := Idempotency(newIdempotencyStore(), time.Hour)(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body, _ := io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"call":%d,"echo":%s}`, calls, body)
+			})
+
+			var first string
+			for i, req := range tt.requests {
+				path := req.path
+				if path == "" {
+					path = "/api/students"
+				}
+				r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(req.body))
+				if req.key != "" {
+					r.Header.Set(IdempotencyKeyHeader, req.key)
+				}
+				if req.owner != "" {
+					r = r.WithContext(context.WithValue(r.Context(), student.EmailContextKey(), req.owner))
+				}
+				w := httptest.NewRecorder()
+				h(w, r)
+
+				if w.Code != req.status {
+					t.Fatalf("request %d: status = %d, want %d: %s", i, w.Code, req.status, w.Body)
+				}
+				if got := w.Header().Get(IdempotentReplayedHeader) == "true"; got != req.replayed {
+					t.Fatalf("request %d: replayed = %v, want %v", i, got, req.replayed)
+				}
+				if i == 0 {
+					first = w.Body.String()
+				} else if req.replayed {
+					if w.Body.String() != first || w.Header().Get("Content-Type") != "application/json" {
+						t.Fatalf("request %d: replayed %q, want %q", i, w.Body, first)
+					}
+				}
+			}
+
+			if calls != tt.calls {
+				t.Fatalf("handler ran %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	store := newIdempotencyStore()
+	started, release := make(chan struct{}), make(chan struct{})
+	h := Idempotency(store, time.Hour)(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/students", strings.NewReader(`{}`))
+		r.Header.Set(IdempotencyKeyHeader, "k1")
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send() }()
+	<-started
+
+	if w := send(); w.Code != http.StatusConflict {
+		t.Fatalf("retry while in flight: status = %d, want 409", w.Code)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("first request: status = %d, want 201", w.Code)
+	}
+	if w := send(); w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("retry after completion: status = %d, replayed = %q", w.Code, w.Header().Get(IdempotentReplayedHeader))
+	}
+}
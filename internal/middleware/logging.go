@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/requestid"
+)
+
+type requestInfoKey struct{}
+
+// requestInfo is created by AccessLog and filled in further down the chain.
+// Inner middleware and handlers only ever see copies of the request, so the
+// matched route and user are passed back up through this shared pointer.
+type requestInfo struct {
+	route string
+	email string
+}
+
+// statusRecorder captures the status code and body size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// RequestID propagates a valid incoming X-Request-ID or assigns a new one,
+// echoing it on the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithContext(r.Context(), id)))
+	})
+}
+
+// AccessLog stores a request-scoped logger in the context and emits one log
+// line per request once the handler returns. It must be wrapped by RequestID.
+func AccessLog(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info := &requestInfo{}
+			reqLogger := base.With(slog.String("request_id", requestid.FromContext(r.Context())))
+
+			ctx := logger.WithContext(r.Context(), reqLogger)
+			ctx = context.WithValue(ctx, requestInfoKey{}, info)
+			r = r.WithContext(ctx)
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			reqLogger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+				slog.String("method", r.Method),
+				slog.String("route", routeOf(r)),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.status),
+				slog.Int("bytes", rec.bytes),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("user", info.email),
+			)
+		})
+	}
+}
+
+// MatchedRoute must wrap the router directly. It publishes the route pattern
+// the router matched so outer middleware can label logs, metrics and spans.
+func MatchedRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+			info.route = r.Pattern
+		}
+	})
+}
+
+// routeOf returns the route pattern matched for r, once the router has run.
+func routeOf(r *http.Request) string {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok && info.route != "" {
+		return info.route
+	}
+	return r.Pattern
+}
+
+// setAccessLogUser records the authenticated user for the access log line and
+// returns a context whose logger carries the user as well.
+func setAccessLogUser(ctx context.Context, email string) context.Context {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.email = email
+	}
+	return logger.WithContext(ctx, logger.FromContext(ctx).With(slog.String("user", email)))
+}
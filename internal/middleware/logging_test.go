@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "assigned when missing"},
+		{name: "propagated when valid", incoming: "client-abc-123", keep: true},
+		{name: "replaced when invalid", incoming: "bad id\r\nX-Injected: 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = requestid.FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+			if tt.incoming != "" {
+				r.Header.Set(requestid.Header, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			echoed := w.Header().Get(requestid.Header)
+			if echoed != seen || !requestid.Valid(echoed) {
+				t.Fatalf("echoed %q, handler saw %q", echoed, seen)
+			}
+			if (echoed == tt.incoming) != tt.keep {
+				t.Fatalf("request ID = %q, incoming %q", echoed, tt.incoming)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		auth   bool
+		route  string
+		status int
+		bytes  float64
+		user   string
+	}{
+		{name: "public route", path: "/api/health", route: "GET /api/health", status: http.StatusOK, bytes: 2},
+		{name: "authenticated route", path: "/api/students/7", auth: true, route: "GET /api/students/{id}", status: http.StatusAccepted, bytes: 2, user: "ada@example.com"},
+		{name: "rejected token", path: "/api/students/7", route: "GET /api/students/{id}", status: http.StatusUnauthorized},
+		{name: "no route", path: "/nothing", route: "", status: http.StatusNotFound},
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	router.HandleFunc("GET /api/students/{id}", JWTAuth(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("handled")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("{}"))
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			base := slog.New(slog.NewJSONHandler(&buf, nil))
+			h := RequestID(AccessLog(base)(MatchedRoute(router)))
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Header.Set(requestid.Header, "req-1")
+			if tt.auth {
+				r.Header.Set("Authorization", bearer(t, "ada@example.com"))
+			} else if tt.status == http.StatusUnauthorized {
+				r.Header.Set("Authorization", "Bearer forged")
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			var entry map[string]any
+			if err := json.Unmarshal(lines[len(lines)-1], &entry); err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]any{
+				"msg":        "request",
+				"request_id": "req-1",
+				"method":     "GET",
+				"route":      tt.route,
+				"path":       tt.path,
+				"status":     float64(tt.status),
+				"user":       tt.user,
+			}
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("%s = %v, want %v", key, entry[key], value)
+				}
+			}
+			if tt.bytes != 0 && entry["bytes"] != tt.bytes {
+				t.Errorf("bytes = %v, want %v", entry["bytes"], tt.bytes)
+			}
+
+			if tt.auth {
+				var handled map[string]any
+				if err := json.Unmarshal(lines[0], &handled); err != nil {
+					t.Fatal(err)
+				}
+				if handled["msg"] != "handled" || handled["request_id"] != "req-1" || handled["user"] != "ada@example.com" {
+					t.Errorf("handler log line = %v, want it tagged with the request ID and user", handled)
+				}
+			}
+		})
+	}
+}
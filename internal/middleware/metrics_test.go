@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/courses/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	h := AccessLog(slog.New(slog.NewTextHandler(io.Discard, nil)))(Metrics(MatchedRoute(router)))
+
+	tests := []struct {
+		path   string
+		route  string
+		status string
+	}{
+		{path: "/api/courses/1", route: "GET /api/courses/{id}", status: "200"},
+		{path: "/api/courses/2", route: "GET /api/courses/{id}", status: "200"},
+		{path: "/api/courses/0", route: "GET /api/courses/{id}", status: "404"},
+		{path: "/random/path/1", route: "unmatched", status: "404"},
+		{path: "/random/path/2", route: "unmatched", status: "404"},
+	}
+
+	before := make(map[[2]string]float64)
+	for _, tt := range tests {
+		key := [2]string{tt.route, tt.status}
+		if _, ok := before[key]; !ok {
+			before[key] = testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(tt.route, http.MethodGet, tt.status))
+		}
+	}
+
+	for _, tt := range tests {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+	}
+
+	want := map[[2]string]float64{
+		{"GET /api/courses/{id}", "200"}: 2,
+		{"GET /api/courses/{id}", "404"}: 1,
+		{"unmatched", "404"}:             2,
+	}
+	for key, n := range want {
+		got := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(key[0], http.MethodGet, key[1])) - before[key]
+		if got != n {
+			t.Errorf("requests for %s with status %s = %g, want %g", key[0], key[1], got, n)
+		}
+	}
+	if inFlight := testutil.ToFloat64(metrics.HTTPRequestsInFlight); inFlight != 0 {
+		t.Errorf("in flight = %g after every request finished, want 0", inFlight)
+	}
+}
@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -13,30 +17,93 @@ var secret_key = []byte("secret_key")
 
 type Claims struct {
 	Email string `json:"email"`
+	Role  string `json:"role"`
 	jwt.RegisteredClaims
 }
 
+var errMissingAuthorization = errors.New("missing authorization header")
+
+// parseToken validates the bearer token on r and returns its claims.
+func parseToken(r *http.Request) (*Claims, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, errMissingAuthorization
+	}
+
+	tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret_key, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+	return claims, nil
+}
+
+// JWTAuth authenticates the bearer token on r. Requests without an
+// Authorization header fall back to a verified client certificate identity.
 func JWTAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		claims, err := parseToken(r)
+		if errors.Is(err, errMissingAuthorization) {
+			if identity, ok := clientCertIdentityFrom(r.Context()); ok {
+				claims, err = identity, nil
+			}
+		}
+		if err != nil {
+			response.WriteError(w, r, http.StatusUnauthorized, response.CodeUnauthorized, err)
 			return
 		}
 
-		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+		ctx := context.WithValue(r.Context(), student.EmailContextKey(), claims.Email)
+		ctx = context.WithValue(ctx, student.RoleContextKey(), claims.Role)
+		ctx = setAccessLogUser(ctx, claims.Email)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return secret_key, nil
-		})
+// RequireAdmin must be wrapped by JWTAuth so the role is already in context.
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, _ := r.Context().Value(student.RoleContextKey()).(string)
+		if role != types.RoleAdmin {
+			response.WriteError(w, r, http.StatusForbidden, response.CodeForbidden, errors.New("admin role required"))
+			return
+		}
 
-		if err != nil || !token.Valid {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		next.ServeHTTP(w, r)
+	}
+}
+
+// Acceptable rejects a request with 406 before it reaches next when the
+// response could not be rendered in any format its Accept header allows.
+// Handlers with side effects need it; read-only ones can fail in Render.
+func Acceptable(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, problem := response.Negotiate(r); problem != nil {
+			response.WriteProblem(w, r, problem)
 			return
 		}
+		next(w, r)
+	}
+}
 
-		ctx := context.WithValue(r.Context(), student.EmailContextKey(), claims.Email)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// MaxBodyBytes caps the size of every request body. Reads past the limit fail
+// with *http.MaxBytesError, which request.Decode reports as 413.
+func MaxBodyBytes(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				response.WriteError(w, r, http.StatusRequestEntityTooLarge, response.CodePayloadTooLarge,
+					fmt.Errorf("request body must not exceed %d bytes", limit))
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
 	}
 }
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+	"golang.org/x/time/rate"
+)
+
+// idleBucketTTL is how long a client's bucket is kept after its last request.
+const idleBucketTTL = 10 * time.Minute
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter enforces a token bucket per client key and reports the state of
+// the bucket in RateLimit-* headers.
+type RateLimiter struct {
+	rate  rate.Limit
+	burst int
+	key   func(*http.Request) string
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter builds a limiter of ratePerSecond tokens with the given
+// burst, keyed as configured by cfg.KeyBy.
+func NewRateLimiter(cfg config.RateLimit, ratePerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:      rate.Limit(ratePerSecond),
+		burst:     burst,
+		key:       clientKey(cfg),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *RateLimiter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		limiter := l.limiterFor(l.key(r), now)
+		allowed := limiter.AllowN(now, 1)
+		tokens := limiter.TokensAt(now)
+
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(l.burst))
+		header.Set("RateLimit-Remaining", strconv.Itoa(int(math.Max(0, math.Floor(tokens)))))
+		header.Set("RateLimit-Reset", strconv.Itoa(l.secondsUntil(float64(l.burst), tokens)))
+
+		if !allowed {
+			retryAfter := l.secondsUntil(1, tokens)
+			header.Set("Retry-After", strconv.Itoa(retryAfter))
+			response.WriteError(w, r, http.StatusTooManyRequests, response.CodeRateLimited,
+				fmt.Errorf("rate limit exceeded, retry in %d seconds", retryAfter))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// secondsUntil returns how long, rounded up, until the bucket holds target tokens.
+func (l *RateLimiter) secondsUntil(target, tokens float64) int {
+	if tokens >= target || l.rate <= 0 {
+		return 0
+	}
+	return int(math.Ceil((target - tokens) / float64(l.rate)))
+}
+
+func (l *RateLimiter) limiterFor(key string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleBucketTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > idleBucketTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
+
+func clientKey(cfg config.RateLimit) func(*http.Request) string {
+	ip := func(r *http.Request) string {
+		return "ip:" + clientIP(r, cfg.TrustProxy)
+	}
+
+	switch cfg.KeyBy {
+	case "subject":
+		return func(r *http.Request) string {
+			if claims, err := parseToken(r); err == nil && claims.Email != "" {
+				return "sub:" + claims.Email
+			}
+			return ip(r)
+		}
+	case "api_key":
+		// Only known keys get a bucket of their own; anything else would let a
+		// client pick a fresh bucket per request and grow the map without end.
+		known := make(map[string]bool, len(cfg.APIKeys))
+		for _, key := range cfg.APIKeys {
+			known[key] = true
+		}
+		return func(r *http.Request) string {
+			if key := r.Header.Get(cfg.APIKeyHeader); known[key] {
+				return "key:" + key
+			}
+			return ip(r)
+		}
+	default:
+		return ip
+	}
+}
+
+// clientIP returns the peer address, or the right-most X-Forwarded-For entry
+// when the service runs behind a trusted proxy. That entry is the one the
+// proxy appended; anything left of it came from the client and may be forged.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			last := values[len(values)-1]
+			if i := strings.LastIndex(last, ","); i >= 0 {
+				last = last[i+1:]
+			}
+			if ip := strings.TrimSpace(last); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
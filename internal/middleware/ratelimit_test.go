@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRateLimiter(t *testing.T) {
+	type request struct {
+		remoteAddr string
+		header     http.Header
+		status     int
+		remaining  int
+	}
+
+	token := bearer(t, "ada@example.com")
+
+	tests := []struct {
+		name     string
+		cfg      config.RateLimit
+		requests []request
+	}{
+		{
+			name: "burst then limited",
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.1:1001", status: http.StatusOK, remaining: 0},
+				{remoteAddr: "10.0.0.1:1002", status: http.StatusTooManyRequests, remaining: 0},
+			},
+		},
+		{
+			name: "separate buckets per address",
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.1:1000", status: http.StatusOK, remaining: 0},
+				{remoteAddr: "10.0.0.2:1000", status: http.StatusOK, remaining: 1},
+			},
+		},
+		{
+			name: "forwarded address ignored without a trusted proxy",
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Forwarded-For": {"1.1.1.1"}}, status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Forwarded-For": {"2.2.2.2"}}, status: http.StatusOK, remaining: 0},
+			},
+		},
+		{
+			name: "forwarded address behind a trusted proxy",
+			cfg:  config.RateLimit{TrustProxy: true},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Forwarded-For": {"1.1.1.1"}}, status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Forwarded-For": {"2.2.2.2"}}, status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Forwarded-For": {"9.9.9.9, 2.2.2.2"}}, status: http.StatusOK, remaining: 0},
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Forwarded-For": {"8.8.8.8", "1.1.1.1"}}, status: http.StatusOK, remaining: 0},
+			},
+		},
+		{
+			name: "api key",
+			cfg:  config.RateLimit{KeyBy: "api_key", APIKeyHeader: "X-API-Key", APIKeys: []string{"k1"}},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Api-Key": {"k1"}}, status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.2:1000", header: http.Header{"X-Api-Key": {"k1"}}, status: http.StatusOK, remaining: 0},
+				{remoteAddr: "10.0.0.1:1000", status: http.StatusOK, remaining: 1},
+			},
+		},
+		{
+			name: "unknown api keys share the address bucket",
+			cfg:  config.RateLimit{KeyBy: "api_key", APIKeyHeader: "X-API-Key", APIKeys: []string{"k1"}},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Api-Key": {"made-up-1"}}, status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Api-Key": {"made-up-2"}}, status: http.StatusOK, remaining: 0},
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"X-Api-Key": {"made-up-3"}}, status: http.StatusTooManyRequests, remaining: 0},
+			},
+		},
+		{
+			name: "subject",
+			cfg:  config.RateLimit{KeyBy: "subject"},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", header: http.Header{"Authorization": {token}}, status: http.StatusOK, remaining: 1},
+				{remoteAddr: "10.0.0.2:1000", header: http.Header{"Authorization": {token}}, status: http.StatusOK, remaining: 0},
+				{remoteAddr: "10.0.0.2:1000", header: http.Header{"Authorization": {"Bearer forged"}}, status: http.StatusOK, remaining: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A rate this low never refills a token while the test runs.
+			limiter := NewRateLimiter(tt.cfg, 0.001, 2)
+			h := limiter.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			for i, req := range tt.requests {
+				r := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+				r.RemoteAddr = req.remoteAddr
+				for name, values := range req.header {
+					r.Header[name] = values
+				}
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+
+				if w.Code != req.status {
+					t.Fatalf("request %d: status = %d, want %d", i, w.Code, req.status)
+				}
+				if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+					t.Errorf("request %d: RateLimit-Limit = %q, want 2", i, got)
+				}
+				if got := w.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(req.remaining) {
+					t.Errorf("request %d: RateLimit-Remaining = %q, want %d", i, got, req.remaining)
+				}
+				retryAfter := w.Header().Get("Retry-After")
+				if (req.status == http.StatusTooManyRequests) != (retryAfter != "") {
+					t.Errorf("request %d: Retry-After = %q with status %d", i, retryAfter, w.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	const burst, clients = 5, 50
+
+	limiter := NewRateLimiter(config.RateLimit{}, 0.001, burst)
+	h := limiter.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/students", nil))
+			if w.Code == http.StatusOK {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != burst {
+		t.Fatalf("%d of %d concurrent requests allowed, want %d", allowed, clients, burst)
+	}
+}
+
+func bearer(t *testing.T, email string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{Email: email}).SignedString(secret_key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
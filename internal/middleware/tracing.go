@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for every request, continuing any trace
+// received in a W3C traceparent header. It must sit inside AccessLog and
+// outside MatchedRoute so the span can be renamed after the matched route.
+func Tracing(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		if route := routeOf(r); route != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+	})
+
+	return otelhttp.NewHandler(named, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+	)
+}
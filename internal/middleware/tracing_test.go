@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		spanName    string
+		route       string
+	}{
+		{name: "named after the route", path: "/api/courses/7", spanName: "GET /api/courses/{id}", route: "GET /api/courses/{id}"},
+		{name: "unmatched keeps the method", path: "/nothing", spanName: "GET"},
+		{name: "continues an incoming trace", path: "/api/courses/7", traceparent: "00-" + traceID + "-00f067aa0ba902b7-01", spanName: "GET /api/courses/{id}", route: "GET /api/courses/{id}"},
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/courses/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+
+			h := AccessLog(slog.New(slog.NewTextHandler(io.Discard, nil)))(Tracing(MatchedRoute(router)))
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				r.Header.Set("traceparent", tt.traceparent)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("recorded %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.spanName {
+				t.Fatalf("span name = %q, want %q", span.Name(), tt.spanName)
+			}
+
+			route := ""
+			for _, attr := range span.Attributes() {
+				if attr.Key == "http.route" {
+					route = attr.Value.AsString()
+				}
+			}
+			if route != tt.route {
+				t.Fatalf("http.route = %q, want %q", route, tt.route)
+			}
+
+			continued := span.SpanContext().TraceID().String() == traceID
+			if continued != (tt.traceparent != "") {
+				t.Fatalf("trace ID = %s with traceparent %q", span.SpanContext().TraceID(), tt.traceparent)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// spec is maintained by hand next to the routes in internal/router. CheckRoutes
+// keeps the two in sync at startup.
+//
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docs []byte
+
+// Handler serves the OpenAPI document.
+func Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// Docs serves a self-contained page that renders the OpenAPI document.
+func Docs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(docs)
+	}
+}
+
+// CheckRoutes returns an error listing every ServeMux pattern, such as
+// "GET /api/students/{id}", that the OpenAPI document does not describe.
+func CheckRoutes(patterns []string) error {
+	var document struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &document); err != nil {
+		return fmt.Errorf("parse openapi document: %w", err)
+	}
+
+	var missing []string
+	for _, pattern := range patterns {
+		method, path, found := strings.Cut(pattern, " ")
+		if !found {
+			missing = append(missing, pattern+" (routes must declare a method)")
+			continue
+		}
+		if _, ok := document.Paths[path][strings.ToLower(method)]; !ok {
+			missing = append(missing, pattern)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes missing from the openapi document: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
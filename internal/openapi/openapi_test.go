@@ -0,0 +1,55 @@
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	config "github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/Saidurbu/go-lang-crud/internal/grading"
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/health"
+	"github.com/Saidurbu/go-lang-crud/internal/openapi"
+	"github.com/Saidurbu/go-lang-crud/internal/router"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	scales, err := grading.New(config.Grading{DefaultScale: "letter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := router.New(&config.Config{}, nil, scales, &health.State{})
+
+	if len(mux.Patterns()) == 0 {
+		t.Fatal("router registered no routes")
+	}
+	if err := openapi.CheckRoutes(mux.Patterns()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		missing  string
+	}{
+		{name: "documented", patterns: []string{"GET /api/students", "POST /api/students/{id}/restore"}},
+		{name: "undocumented path", patterns: []string{"GET /api/teachers"}, missing: "GET /api/teachers"},
+		{name: "undocumented method", patterns: []string{"PATCH /api/students/{id}"}, missing: "PATCH /api/students/{id}"},
+		{name: "no method", patterns: []string{"/api/students"}, missing: "/api/students (routes must declare a method)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := openapi.CheckRoutes(tt.patterns)
+			if tt.missing == "" {
+				if err != nil {
+					t.Fatalf("CheckRoutes() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.missing) {
+				t.Fatalf("CheckRoutes() = %v, want it to list %q", err, tt.missing)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Package router registers the API's routes, so the server and its tests
+// serve the same set.
+package router
+
+import (
+	"net/http"
+
+	config "github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/Saidurbu/go-lang-crud/internal/grading"
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/audit"
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/course"
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/health"
+	"github.com/Saidurbu/go-lang-crud/internal/handlers/student"
+	"github.com/Saidurbu/go-lang-crud/internal/metrics"
+	"github.com/Saidurbu/go-lang-crud/internal/middleware"
+	"github.com/Saidurbu/go-lang-crud/internal/openapi"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+)
+
+// Mux records the pattern of every route it registers, so they can be
+// checked against the OpenAPI document.
+type Mux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (m *Mux) Handle(pattern string, handler http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.Handle(pattern, handler)
+}
+
+func (m *Mux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.HandleFunc(pattern, handler)
+}
+
+// Patterns returns the patterns registered so far, in order.
+func (m *Mux) Patterns() []string {
+	return m.patterns
+}
+
+// New returns a mux with every API route registered. Middleware that applies
+// to all routes, such as the general rate limit, is left to the caller.
+func New(cfg *config.Config, store storage.Storage, scales *grading.Scales, healthState *health.State) *Mux {
+	router := &Mux{ServeMux: http.NewServeMux()}
+
+	authLimit := func(next http.Handler) http.Handler { return next }
+	if cfg.RateLimit.Enabled {
+		authLimit = middleware.NewRateLimiter(cfg.RateLimit, cfg.RateLimit.AuthRate, cfg.RateLimit.AuthBurst).Wrap
+	}
+
+	router.Handle("GET /metrics", metrics.Handler())
+	router.HandleFunc("GET /openapi.json", openapi.Handler())
+	router.HandleFunc("GET /docs", openapi.Docs())
+
+	router.HandleFunc("GET /healthz", health.Liveness())
+	router.HandleFunc("GET /readyz", health.Readiness(store, healthState))
+
+	idempotent := middleware.Idempotency(store, cfg.Idempotency.TTL)
+
+	router.Handle("POST /api/registration", authLimit(middleware.Acceptable(idempotent(student.Registration(store)))))
+	router.Handle("POST /api/login", authLimit(middleware.Acceptable(student.Login(store))))
+
+	router.HandleFunc("GET /api/profile", middleware.JWTAuth(student.GetProfile(store)))
+
+	router.HandleFunc("GET /api/students", middleware.JWTAuth(student.GetList(store)))
+	router.HandleFunc("POST /api/students", middleware.JWTAuth(middleware.Acceptable(idempotent(student.New(store)))))
+	router.HandleFunc("GET /api/students/search", middleware.JWTAuth(student.Search(store)))
+	router.HandleFunc("GET /api/students/export", middleware.JWTAuth(student.Export(store)))
+	router.HandleFunc("POST /api/students/import", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(idempotent(student.Import(store))))))
+	router.HandleFunc("GET /api/students/{id}", middleware.JWTAuth(student.GetById(store)))
+	router.HandleFunc("PUT /api/students/{id}", middleware.JWTAuth(middleware.Acceptable(student.Update(store))))
+	router.HandleFunc("DELETE /api/students/{id}", middleware.JWTAuth(middleware.Acceptable(student.Delete(store))))
+	router.HandleFunc("POST /api/students/{id}/restore", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(idempotent(student.Restore(store))))))
+
+	router.HandleFunc("GET /api/students/{id}/courses", middleware.JWTAuth(course.StudentCourses(store)))
+	router.HandleFunc("GET /api/students/{id}/transcript", middleware.JWTAuth(course.Transcript(store)))
+
+	router.HandleFunc("GET /api/courses", middleware.JWTAuth(course.GetList(store)))
+	router.HandleFunc("POST /api/courses", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(idempotent(course.New(store))))))
+	router.HandleFunc("GET /api/courses/{id}", middleware.JWTAuth(course.GetById(store)))
+	router.HandleFunc("PUT /api/courses/{id}", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(course.Update(store)))))
+	router.HandleFunc("DELETE /api/courses/{id}", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(course.Delete(store)))))
+
+	router.HandleFunc("GET /api/enrollments", middleware.JWTAuth(course.GetEnrollments(store)))
+	router.HandleFunc("POST /api/enrollments", middleware.JWTAuth(middleware.Acceptable(idempotent(course.Enroll(store)))))
+	router.HandleFunc("GET /api/enrollments/{id}", middleware.JWTAuth(course.GetEnrollment(store)))
+	router.HandleFunc("PUT /api/enrollments/{id}", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(course.UpdateEnrollment(store)))))
+	router.HandleFunc("DELETE /api/enrollments/{id}", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(course.DeleteEnrollment(store)))))
+	router.HandleFunc("GET /api/enrollments/{id}/grade", middleware.JWTAuth(course.GetGrade(store)))
+	router.HandleFunc("PUT /api/enrollments/{id}/grade", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(course.SetGrade(store, scales)))))
+	router.HandleFunc("DELETE /api/enrollments/{id}/grade", middleware.JWTAuth(middleware.RequireAdmin(middleware.Acceptable(course.DeleteGrade(store)))))
+	router.HandleFunc("GET /api/grading-scales", middleware.JWTAuth(course.GradingScales(scales)))
+
+	router.HandleFunc("GET /api/audit", middleware.JWTAuth(middleware.RequireAdmin(audit.GetList(store))))
+
+	return router
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPasswords replaces each student's plain text password with its bcrypt
+// hash. bcrypt is deliberately slow, so bulk inserts hash on every CPU, once,
+// before calling CreateStudents. Rows that cannot be hashed keep their plain
+// text password and the error joins a *RowError for each of them, in order.
+func HashPasswords(students []types.Student) error {
+	errs := make([]error, len(students))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if students[i].Password == "" {
+					errs[i] = fmt.Errorf("password is required: %w", ErrValidation)
+					continue
+				}
+				hashed, err := HashPassword(students[i].Password)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				students[i].Password = hashed
+			}
+		}()
+	}
+
+	for i := range students {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var rowErrs []error
+	for i, err := range errs {
+		if err != nil {
+			rowErrs = append(rowErrs, &RowError{Index: i, Err: err})
+		}
+	}
+	return errors.Join(rowErrs...)
+}
+
+// HashPassword returns the bcrypt hash of password. bcrypt only takes 72
+// bytes, which validation counts in characters, so a longer multi-byte
+// password is reported as ErrValidation rather than failing the request.
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", fmt.Errorf("password must not exceed 72 bytes: %w", ErrValidation)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPasswords(t *testing.T) {
+	tests := []struct {
+		name      string
+		passwords []string
+		failed    []int
+	}{
+		{name: "none", passwords: nil},
+		{name: "all valid", passwords: []string{"Passw0rd!", "An0ther-one"}},
+		{name: "missing and too long", passwords: []string{"", "Passw0rd!", strings.Repeat("x", 73), "fine-Passw0rd"}, failed: []int{0, 2}},
+		{name: "exactly 72 bytes", passwords: []string{strings.Repeat("x", 72)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			students := make([]types.Student, len(tt.passwords))
+			for i, password := range tt.passwords {
+				students[i].Password = password
+			}
+
+			err := HashPasswords(students)
+
+			var failed []int
+			if err != nil {
+				for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var rowErr *RowError
+					if !errors.As(err, &rowErr) || !errors.Is(err, ErrValidation) {
+						t.Fatalf("error %v is not a validation *RowError", err)
+					}
+					failed = append(failed, rowErr.Index)
+				}
+			}
+			if !slices.Equal(failed, tt.failed) {
+				t.Fatalf("failed rows = %v, want %v", failed, tt.failed)
+			}
+
+			for i, student := range students {
+				if slices.Contains(tt.failed, i) {
+					if student.Password != tt.passwords[i] {
+						t.Errorf("row %d: failed password was changed", i)
+					}
+					continue
+				}
+				if bcrypt.CompareHashAndPassword([]byte(student.Password), []byte(tt.passwords[i])) != nil {
+					t.Errorf("row %d: password was not replaced by its hash", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Backends wrap these sentinels so callers can tell failure kinds apart with
+// errors.Is instead of matching on message text.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// RowError reports which element of a bulk operation failed. It wraps the
+// underlying error, so errors.Is still matches the sentinels above.
+type RowError struct {
+	Index int
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activeStudents restricts enrollments to students that are not deleted, so
+// a deleted student no longer holds a seat.
+const activeStudents = "enrollments.student_id IN (SELECT id FROM students WHERE deleted_at IS NULL)"
+
+// enrolledCount selects a course's Enrolled count alongside its columns.
+const enrolledCount = "courses.*, (SELECT COUNT(*) FROM enrollments WHERE enrollments.course_id = courses.id AND enrollments.status = ? AND " + activeStudents + ") AS enrolled"
+
+func (p *Postgres) CreateCourse(ctx context.Context, course types.Course) (uint, error) {
+	row := types.Course{Code: course.Code, Title: course.Title, Credits: course.Credits, Capacity: course.Capacity}
+	if err := p.DB.WithContext(ctx).Create(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return 0, fmt.Errorf("course code %s already exists: %w", course.Code, storage.ErrConflict)
+		}
+		return 0, err
+	}
+	return row.ID, nil
+}
+
+func (p *Postgres) GetCourse(ctx context.Context, id uint) (types.Course, error) {
+	var course types.Course
+	err := p.DB.WithContext(ctx).Model(&types.Course{}).Select(enrolledCount, types.EnrollmentStatusEnrolled).
+		Where("courses.id = ?", id).Take(&course).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.Course{}, fmt.Errorf("course not found with id %d: %w", id, storage.ErrNotFound)
+		}
+		return types.Course{}, fmt.Errorf("query error: %w", err)
+	}
+	return course, nil
+}
+
+func (p *Postgres) GetCourses(ctx context.Context) ([]types.Course, error) {
+	var courses []types.Course
+	err := p.DB.WithContext(ctx).Model(&types.Course{}).Select(enrolledCount, types.EnrollmentStatusEnrolled).
+		Order("code").Find(&courses).Error
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return courses, nil
+}
+
+func (p *Postgres) UpdateCourse(ctx context.Context, course types.Course) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockCourse(tx, course.ID); err != nil {
+			return err
+		}
+
+		enrolled, err := countEnrolled(tx, course.ID)
+		if err != nil {
+			return err
+		}
+		if int64(course.Capacity) < enrolled {
+			return fmt.Errorf("capacity %d is below the %d students enrolled: %w", course.Capacity, enrolled, storage.ErrConflict)
+		}
+
+		err = tx.Model(&types.Course{}).Where("id = ?", course.ID).Updates(map[string]any{
+			"code":       course.Code,
+			"title":      course.Title,
+			"credits":    course.Credits,
+			"capacity":   course.Capacity,
+			"updated_at": time.Now(),
+		}).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("course code %s already exists: %w", course.Code, storage.ErrConflict)
+		}
+		return err
+	})
+}
+
+func (p *Postgres) DeleteCourse(ctx context.Context, id uint) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockCourse(tx, id); err != nil {
+			return err
+		}
+
+		var enrollments int64
+		if err := tx.Model(&types.Enrollment{}).Where("course_id = ?", id).Count(&enrollments).Error; err != nil {
+			return err
+		}
+		if enrollments > 0 {
+			return fmt.Errorf("course %d has %d enrollments: %w", id, enrollments, storage.ErrConflict)
+		}
+
+		return tx.Delete(&types.Course{}, id).Error
+	})
+}
+
+// lockCourse reads a course with a row lock held until the transaction ends,
+// which serialises every capacity check on that course.
+func lockCourse(tx *gorm.DB, id uint) (types.Course, error) {
+	var course types.Course
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&course, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.Course{}, fmt.Errorf("course not found with id %d: %w", id, storage.ErrNotFound)
+		}
+		return types.Course{}, err
+	}
+	return course, nil
+}
+
+func countEnrolled(tx *gorm.DB, courseID uint) (int64, error) {
+	var enrolled int64
+	err := tx.Model(&types.Enrollment{}).
+		Where("course_id = ? AND status = ? AND "+activeStudents, courseID, types.EnrollmentStatusEnrolled).
+		Count(&enrolled).Error
+	return enrolled, err
+}
+
+// reserveSeat fails with ErrConflict when course has no seat left.
+func reserveSeat(tx *gorm.DB, course types.Course) error {
+	enrolled, err := countEnrolled(tx, course.ID)
+	if err != nil {
+		return err
+	}
+	if enrolled >= int64(course.Capacity) {
+		return fmt.Errorf("course %s is full: %w", course.Code, storage.ErrConflict)
+	}
+	return nil
+}
+
+func (p *Postgres) CreateEnrollment(ctx context.Context, studentID uint, courseID uint) (uint, error) {
+	var id uint
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		course, err := lockCourse(tx, courseID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Take(&types.Student{}, studentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("student not found with id %d: %w", studentID, storage.ErrNotFound)
+			}
+			return err
+		}
+
+		var existing types.Enrollment
+		err = tx.Where("student_id = ? AND course_id = ?", studentID, courseID).Take(&existing).Error
+		switch {
+		case err == nil && existing.Status == types.EnrollmentStatusDropped:
+			// Re-enrolling after dropping reuses the enrollment.
+			if err := reserveSeat(tx, course); err != nil {
+				return err
+			}
+			id = existing.ID
+			return tx.Model(&existing).Updates(map[string]any{"status": types.EnrollmentStatusEnrolled, "updated_at": time.Now()}).Error
+		case err == nil:
+			return fmt.Errorf("student %d is already %s in course %s: %w", studentID, existing.Status, course.Code, storage.ErrConflict)
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		if err := reserveSeat(tx, course); err != nil {
+			return err
+		}
+
+		enrollment := types.Enrollment{StudentID: studentID, CourseID: courseID, Status: types.EnrollmentStatusEnrolled}
+		if err := tx.Create(&enrollment).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("student %d is already in course %s: %w", studentID, course.Code, storage.ErrConflict)
+			}
+			return err
+		}
+		id = enrollment.ID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (p *Postgres) GetEnrollment(ctx context.Context, id uint) (types.Enrollment, error) {
+	var enrollment types.Enrollment
+	if err := p.DB.WithContext(ctx).Take(&enrollment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.Enrollment{}, fmt.Errorf("enrollment not found with id %d: %w", id, storage.ErrNotFound)
+		}
+		return types.Enrollment{}, fmt.Errorf("query error: %w", err)
+	}
+	return enrollment, nil
+}
+
+func (p *Postgres) GetEnrollments(ctx context.Context, filter types.EnrollmentFilter) ([]types.Enrollment, error) {
+	db := p.DB.WithContext(ctx).Model(&types.Enrollment{})
+	if filter.StudentID != 0 {
+		db = db.Where("student_id = ?", filter.StudentID)
+	}
+	if filter.CourseID != 0 {
+		db = db.Where("course_id = ?", filter.CourseID)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+
+	var enrollments []types.Enrollment
+	if err := db.Order("id").Find(&enrollments).Error; err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return enrollments, nil
+}
+
+func (p *Postgres) UpdateEnrollmentStatus(ctx context.Context, id uint, status string) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var enrollment types.Enrollment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&enrollment, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("enrollment not found with id %d: %w", id, storage.ErrNotFound)
+			}
+			return err
+		}
+
+		if status == types.EnrollmentStatusEnrolled && enrollment.Status != types.EnrollmentStatusEnrolled {
+			course, err := lockCourse(tx, enrollment.CourseID)
+			if err != nil {
+				return err
+			}
+			if err := reserveSeat(tx, course); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&enrollment).Updates(map[string]any{"status": status, "updated_at": time.Now()}).Error
+	})
+}
+
+func (p *Postgres) DeleteEnrollment(ctx context.Context, id uint) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("enrollment_id = ?", id).Delete(&types.Grade{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&types.Enrollment{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("enrollment not found with id %d: %w", id, storage.ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (p *Postgres) GetStudentCourses(ctx context.Context, studentID uint) ([]types.StudentCourse, error) {
+	if err := p.DB.WithContext(ctx).Take(&types.Student{}, studentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("student not found with id %d: %w", studentID, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var enrollments []types.Enrollment
+	err := p.DB.WithContext(ctx).Where("student_id = ?", studentID).Order("created_at, id").Find(&enrollments).Error
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	if len(enrollments) == 0 {
+		return []types.StudentCourse{}, nil
+	}
+
+	courseIDs := make([]uint, len(enrollments))
+	enrollmentIDs := make([]uint, len(enrollments))
+	for i, enrollment := range enrollments {
+		courseIDs[i] = enrollment.CourseID
+		enrollmentIDs[i] = enrollment.ID
+	}
+	var courses []types.Course
+	err = p.DB.WithContext(ctx).Model(&types.Course{}).Select(enrolledCount, types.EnrollmentStatusEnrolled).
+		Where("courses.id IN ?", courseIDs).Find(&courses).Error
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	grades, err := gradesByEnrollment(p.DB.WithContext(ctx), enrollmentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	byID := make(map[uint]types.Course, len(courses))
+	for _, course := range courses {
+		byID[course.ID] = course
+	}
+	result := make([]types.StudentCourse, len(enrollments))
+	for i, enrollment := range enrollments {
+		result[i] = types.StudentCourse{
+			EnrollmentID: enrollment.ID,
+			Status:       enrollment.Status,
+			EnrolledAt:   enrollment.CreatedAt,
+			Course:       byID[enrollment.CourseID],
+			Grade:        grades[enrollment.ID],
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (p *Postgres) SetGrade(ctx context.Context, grade types.Grade) (types.Grade, error) {
+	var saved types.Grade
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var enrollment types.Enrollment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&enrollment, grade.EnrollmentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("enrollment not found with id %d: %w", grade.EnrollmentID, storage.ErrNotFound)
+			}
+			return err
+		}
+		if enrollment.Status == types.EnrollmentStatusDropped {
+			return fmt.Errorf("enrollment %d was dropped: %w", enrollment.ID, storage.ErrConflict)
+		}
+
+		row := types.Grade{
+			EnrollmentID: grade.EnrollmentID,
+			Term:         grade.Term,
+			Scale:        grade.Scale,
+			Value:        grade.Value,
+			Letter:       grade.Letter,
+			Points:       grade.Points,
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "enrollment_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"term", "scale", "value", "letter", "points", "updated_at"}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+
+		if enrollment.Status != types.EnrollmentStatusCompleted {
+			err := tx.Model(&enrollment).Updates(map[string]any{"status": types.EnrollmentStatusCompleted, "updated_at": time.Now()}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Where("enrollment_id = ?", grade.EnrollmentID).Take(&saved).Error
+	})
+	if err != nil {
+		return types.Grade{}, err
+	}
+	return saved, nil
+}
+
+func (p *Postgres) GetGrade(ctx context.Context, enrollmentID uint) (types.Grade, error) {
+	var grade types.Grade
+	if err := p.DB.WithContext(ctx).Where("enrollment_id = ?", enrollmentID).Take(&grade).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.Grade{}, fmt.Errorf("no grade recorded for enrollment %d: %w", enrollmentID, storage.ErrNotFound)
+		}
+		return types.Grade{}, fmt.Errorf("query error: %w", err)
+	}
+	return grade, nil
+}
+
+func (p *Postgres) DeleteGrade(ctx context.Context, enrollmentID uint) error {
+	result := p.DB.WithContext(ctx).Where("enrollment_id = ?", enrollmentID).Delete(&types.Grade{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no grade recorded for enrollment %d: %w", enrollmentID, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// gradesByEnrollment loads the grades recorded for enrollmentIDs.
+func gradesByEnrollment(db *gorm.DB, enrollmentIDs []uint) (map[uint]*types.Grade, error) {
+	var grades []types.Grade
+	if err := db.Where("enrollment_id IN ?", enrollmentIDs).Find(&grades).Error; err != nil {
+		return nil, err
+	}
+	byEnrollment := make(map[uint]*types.Grade, len(grades))
+	for i := range grades {
+		byEnrollment[grades[i].EnrollmentID] = &grades[i]
+	}
+	return byEnrollment, nil
+}
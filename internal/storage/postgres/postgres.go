@@ -1,15 +1,17 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
 	"github.com/Saidurbu/go-lang-crud/internal/types"
 	_ "github.com/lib/pq"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -18,29 +20,54 @@ type Postgres struct {
 	DB *gorm.DB
 }
 
+var _ storage.Storage = (*Postgres)(nil)
+
 func New(cfg *config.Config) (*Postgres, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
 
-	db.AutoMigrate(&types.Student{})
-	if err != nil {
+	if err := db.AutoMigrate(&types.Student{}, &types.AuditEntry{}, &types.IdempotencyRecord{}, &types.Course{}, &types.Enrollment{}, &types.Grade{}); err != nil {
+		return nil, err
+	}
+
+	// AutoMigrate adds the timestamp columns as nullable, so students created
+	// before they existed are backfilled here; raw scans into time.Time would
+	// otherwise fail on NULL.
+	if err := db.Exec(`UPDATE students SET created_at = now() WHERE created_at IS NULL;
+		UPDATE students SET updated_at = created_at WHERE updated_at IS NULL`).Error; err != nil {
+		return nil, err
+	}
+
+	// Emails are unique regardless of case among students that are not
+	// soft-deleted, so a deleted student's email can be registered again.
+	// This replaces the earlier plain unique constraint and the index that
+	// also covered deleted rows.
+	if err := db.Exec(`ALTER TABLE students DROP CONSTRAINT IF EXISTS uni_students_email;
+		DROP INDEX IF EXISTS idx_students_email;
+		DROP INDEX IF EXISTS idx_students_email_lower;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_active ON students (LOWER(email)) WHERE deleted_at IS NULL`).Error; err != nil {
+		return nil, err
+	}
+
+	// Search matches whole words through a tsvector index and misspellings
+	// through trigram indexes.
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm;
+		CREATE INDEX IF NOT EXISTS idx_students_search ON students USING GIN (` + searchDocument + `);
+		CREATE INDEX IF NOT EXISTS idx_students_name_trgm ON students USING GIN (name gin_trgm_ops);
+		CREATE INDEX IF NOT EXISTS idx_students_email_trgm ON students USING GIN (email gin_trgm_ops)`).Error; err != nil {
 		return nil, err
 	}
 	log.Println("GORM connected to DB")
 	return &Postgres{DB: db}, nil
 }
 
-func (p *Postgres) CreateStudent(name string, email string, password string, age int) (uint, error) {
-	if password == "" {
-		return 0, fmt.Errorf("password is required")
-	}
-
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (p *Postgres) CreateStudent(ctx context.Context, name string, email string, password string, age int) (uint, error) {
+	hashedPassword, err := storage.HashPassword(password)
 	if err != nil {
 		return 0, err
 	}
@@ -48,38 +75,113 @@ func (p *Postgres) CreateStudent(name string, email string, password string, age
 	student := types.Student{
 		Name:     name,
 		Email:    email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Age:      age,
 	}
 
-	if err := p.DB.Create(&student).Error; err != nil {
+	if err := p.DB.WithContext(ctx).Create(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return 0, fmt.Errorf("email %s already registered: %w", email, storage.ErrConflict)
+		}
 		return 0, err
 	}
 
 	return student.ID, nil
 }
 
-func (p *Postgres) GetStudentById(id uint) (types.Student, error) {
+func (p *Postgres) CreateStudents(ctx context.Context, students []types.Student) ([]uint, error) {
+	rows := make([]types.Student, len(students))
+	for i, student := range students {
+		rows[i] = types.Student{Name: student.Name, Email: student.Email, Password: student.Password, Age: student.Age}
+	}
+
+	ids := make([]uint, 0, len(rows))
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range rows {
+			if err := tx.Create(&rows[i]).Error; err != nil {
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					err = fmt.Errorf("email %s already registered: %w", rows[i].Email, storage.ErrConflict)
+				}
+				return &storage.RowError{Index: i, Err: err}
+			}
+			ids = append(ids, rows[i].ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (p *Postgres) GetStudentById(ctx context.Context, id uint, includeDeleted bool) (types.Student, error) {
+	db := p.DB.WithContext(ctx)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+
 	var student types.Student
-	if err := p.DB.First(&student, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return types.Student{}, fmt.Errorf("student not found with id %d: %w", id, err)
+	if err := db.First(&student, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.Student{}, fmt.Errorf("student not found with id %d: %w", id, storage.ErrNotFound)
 		}
 		return types.Student{}, fmt.Errorf("query error: %w", err)
 	}
 	return student, nil
 }
 
-func (p *Postgres) GetStudents() ([]types.Student, error) {
+// studentsQuery applies filter to a query on the students table.
+func (p *Postgres) studentsQuery(ctx context.Context, filter types.StudentFilter) *gorm.DB {
+	db := p.DB.WithContext(ctx).Model(&types.Student{})
+	if filter.IncludeDeleted {
+		db = db.Unscoped()
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		db = db.Where("created_at > ?", filter.CreatedAfter)
+	}
+	if !filter.UpdatedSince.IsZero() {
+		db = db.Where("updated_at >= ?", filter.UpdatedSince)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+	return db
+}
+
+func (p *Postgres) GetStudents(ctx context.Context, filter types.StudentFilter) ([]types.Student, error) {
 	var students []types.Student
-	if err := p.DB.Find(&students).Error; err != nil {
+	if err := p.studentsQuery(ctx, filter).Order("id").Find(&students).Error; err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
 	return students, nil
 }
 
-func (p *Postgres) GetStudentByEmail(email string) (types.Student, error) {
-	stmt, err := p.DB.Raw("SELECT id, name, email, password, age FROM students WHERE email = $1", email).Rows()
+func (p *Postgres) StreamStudents(ctx context.Context, filter types.StudentFilter, each func(types.Student) error) error {
+	rows, err := p.studentsQuery(ctx, filter).Order("id").Rows()
+	if err != nil {
+		return fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var student types.Student
+		if err := p.DB.ScanRows(rows, &student); err != nil {
+			return err
+		}
+		if err := each(student); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (p *Postgres) GetStudentByEmail(ctx context.Context, email string) (types.Student, error) {
+	stmt, err := p.DB.WithContext(ctx).Raw("SELECT id, name, email, password, age, role, created_at, updated_at FROM students WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL", email).Rows()
 	if err != nil {
 		return types.Student{}, err
 	}
@@ -87,11 +189,11 @@ func (p *Postgres) GetStudentByEmail(email string) (types.Student, error) {
 
 	var student types.Student
 	if stmt.Next() {
-		if err := stmt.Scan(&student.ID, &student.Name, &student.Email, &student.Password, &student.Age); err != nil {
+		if err := stmt.Scan(&student.ID, &student.Name, &student.Email, &student.Password, &student.Age, &student.Role, &student.CreatedAt, &student.UpdatedAt); err != nil {
 			return types.Student{}, err
 		}
 	} else {
-		return types.Student{}, fmt.Errorf("student not found with email %s: %w", email, sql.ErrNoRows)
+		return types.Student{}, fmt.Errorf("student not found with email %s: %w", email, storage.ErrNotFound)
 	}
 
 	return student, nil
@@ -115,12 +217,40 @@ func (p *Postgres) UpdateStudent1(id uint, name string, email string, password s
 	return nil
 }
 
-func (p *Postgres) UpdateStudent(id uint, name, email, password string, age int) error {
+// searchDocument is the indexed text of a student. It must match the
+// expression of idx_students_search for the index to be used.
+const searchDocument = "to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(email, ''))"
+
+func (p *Postgres) SearchStudents(ctx context.Context, search types.StudentSearch) ([]types.StudentMatch, error) {
+	query := "websearch_to_tsquery('simple', @q)"
+	args := map[string]any{"q": search.Query}
+
+	var rows []struct {
+		types.Student
+		Score float64
+	}
+	err := p.studentsQuery(ctx, search.StudentFilter).
+		Select("*, ts_rank("+searchDocument+", "+query+") + greatest(word_similarity(@q, name), word_similarity(@q, email)) AS score", args).
+		Where("("+searchDocument+" @@ "+query+" OR @q <% name OR @q <% email)", args).
+		Order("score DESC, id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	matches := make([]types.StudentMatch, len(rows))
+	for i, row := range rows {
+		matches[i] = types.StudentMatch{Student: row.Student, Score: row.Score}
+	}
+	return matches, nil
+}
+
+func (p *Postgres) UpdateStudent(ctx context.Context, id uint, name, email, password string, age int) error {
 	var student types.Student
 
-	if err := p.DB.First(&student, id).Error; err != nil {
+	if err := p.DB.WithContext(ctx).First(&student, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("student not found with id %d", id)
+			return fmt.Errorf("student not found with id %d: %w", id, storage.ErrNotFound)
 		}
 		return fmt.Errorf("failed to find student: %w", err)
 	}
@@ -130,33 +260,211 @@ func (p *Postgres) UpdateStudent(id uint, name, email, password string, age int)
 	student.Age = age
 
 	if password != "" {
-		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		hashed, err := storage.HashPassword(password)
 		if err != nil {
 			return fmt.Errorf("failed to hash password: %w", err)
 		}
-		student.Password = string(hashed)
+		student.Password = hashed
 	}
 
-	if err := p.DB.Save(&student).Error; err != nil {
+	if err := p.DB.WithContext(ctx).Save(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("email %s already registered: %w", email, storage.ErrConflict)
+		}
 		return fmt.Errorf("failed to update student: %w", err)
 	}
 
 	return nil
 }
 
-func (p *Postgres) DeleteStudent(id uint) error {
+func (p *Postgres) DeleteStudent(ctx context.Context, id uint) error {
 	var student types.Student
-	result := p.DB.First(&student, id)
+	result := p.DB.WithContext(ctx).First(&student, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("student not found with id %d", id)
+			return fmt.Errorf("student not found with id %d: %w", id, storage.ErrNotFound)
 		}
 		return result.Error
 	}
 
-	if err := p.DB.Delete(&student).Error; err != nil {
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&student).Error; err != nil {
+			return err
+		}
+		return tx.Model(&types.Enrollment{}).
+			Where("student_id = ? AND status = ?", id, types.EnrollmentStatusEnrolled).
+			Update("status", types.EnrollmentStatusDropped).Error
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete student: %w", err)
 	}
 
 	return nil
 }
+
+func (p *Postgres) RestoreStudent(ctx context.Context, id uint) error {
+	result := p.DB.WithContext(ctx).Unscoped().Model(&types.Student{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("email of student %d has been registered again: %w", id, storage.ErrConflict)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore student: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("deleted student not found with id %d: %w", id, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// PurgeDeletedStudents removes students deleted before before together with
+// their enrollments and grades, which would otherwise be left orphaned.
+func (p *Postgres) PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("enrollment_id IN (SELECT enrollments.id FROM enrollments JOIN students ON students.id = enrollments.student_id WHERE students.deleted_at < ?)", before).
+			Delete(&types.Grade{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("student_id IN (SELECT id FROM students WHERE deleted_at < ?)", before).Delete(&types.Enrollment{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&types.Student{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge students: %w", err)
+	}
+
+	return purged, nil
+}
+
+func (p *Postgres) CreateAuditEntry(ctx context.Context, entry types.AuditEntry) error {
+	if err := p.DB.WithContext(ctx).Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetAuditEntries(ctx context.Context, filter types.AuditFilter) ([]types.AuditEntry, error) {
+	db := p.DB.WithContext(ctx).Order("created_at DESC, id DESC")
+	if filter.TargetID != 0 {
+		db = db.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Actor != "" {
+		db = db.Where("actor = ?", filter.Actor)
+	}
+	if !filter.Since.IsZero() {
+		db = db.Where("created_at >= ?", filter.Since)
+	}
+
+	var entries []types.AuditEntry
+	if err := db.Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return entries, nil
+}
+
+func (p *Postgres) CreateIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// An expired record no longer holds the key, so it is replaced.
+		err := tx.Where("owner = ? AND idempotency_key = ? AND expires_at <= ?", record.Owner, record.Key, time.Now().UTC()).
+			Delete(&types.IdempotencyRecord{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to create idempotency record: %w", err)
+		}
+
+		if err := tx.Create(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("idempotency key %q is already in use: %w", record.Key, storage.ErrConflict)
+			}
+			return fmt.Errorf("failed to create idempotency record: %w", err)
+		}
+		return nil
+	})
+}
+
+func (p *Postgres) GetIdempotencyRecord(ctx context.Context, owner string, key string) (types.IdempotencyRecord, error) {
+	var record types.IdempotencyRecord
+	err := p.DB.WithContext(ctx).
+		Where("owner = ? AND idempotency_key = ? AND expires_at > ?", owner, key, time.Now().UTC()).
+		First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.IdempotencyRecord{}, fmt.Errorf("idempotency key %q not found: %w", key, storage.ErrNotFound)
+	}
+	if err != nil {
+		return types.IdempotencyRecord{}, fmt.Errorf("query error: %w", err)
+	}
+	return record, nil
+}
+
+func (p *Postgres) CompleteIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error {
+	result := p.DB.WithContext(ctx).Model(&types.IdempotencyRecord{}).
+		Where("owner = ? AND idempotency_key = ?", record.Owner, record.Key).
+		Updates(map[string]interface{}{
+			"status_code":  record.StatusCode,
+			"content_type": record.ContentType,
+			"body":         record.Body,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to complete idempotency record: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("idempotency key %q not found: %w", record.Key, storage.ErrNotFound)
+	}
+	return nil
+}
+
+func (p *Postgres) DeleteIdempotencyRecord(ctx context.Context, owner string, key string) error {
+	err := p.DB.WithContext(ctx).Where("owner = ? AND idempotency_key = ?", owner, key).
+		Delete(&types.IdempotencyRecord{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency record: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) PurgeExpiredIdempotencyRecords(ctx context.Context, before time.Time) (int64, error) {
+	result := p.DB.WithContext(ctx).Where("expires_at <= ?", before).Delete(&types.IdempotencyRecord{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge idempotency records: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (p *Postgres) DBStats() sql.DBStats {
+	db, err := p.DB.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return db.Stats()
+}
+
+func (p *Postgres) Ping(ctx context.Context) error {
+	db, err := p.DB.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+func (p *Postgres) CheckMigrations(ctx context.Context) error {
+	migrator := p.DB.WithContext(ctx).Migrator()
+	for _, model := range []interface{}{&types.Student{}, &types.AuditEntry{}, &types.IdempotencyRecord{}, &types.Course{}, &types.Enrollment{}, &types.Grade{}} {
+		if !migrator.HasTable(model) {
+			return fmt.Errorf("table for %T is missing", model)
+		}
+	}
+	for _, index := range []string{"idx_students_email_active", "idx_students_search", "idx_students_name_trgm", "idx_students_email_trgm"} {
+		if !migrator.HasIndex(&types.Student{}, index) {
+			return fmt.Errorf("index %s is missing", index)
+		}
+	}
+	return nil
+}
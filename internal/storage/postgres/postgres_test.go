@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TestStudentsQuery checks the SQL built for student filters without a
+// database: a dry run session only renders statements.
+func TestStudentsQuery(t *testing.T) {
+	db, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Postgres{DB: db}
+
+	since := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter types.StudentFilter
+		sql    string
+		vars   []any
+	}{
+		{
+			name: "no bounds",
+			sql:  `SELECT * FROM "students" WHERE "students"."deleted_at" IS NULL ORDER BY id`,
+		},
+		{
+			name:   "created after is exclusive",
+			filter: types.StudentFilter{CreatedAfter: since},
+			sql:    `SELECT * FROM "students" WHERE created_at > $1 AND "students"."deleted_at" IS NULL ORDER BY id`,
+			vars:   []any{since},
+		},
+		{
+			name:   "updated since is inclusive",
+			filter: types.StudentFilter{UpdatedSince: since},
+			sql:    `SELECT * FROM "students" WHERE updated_at >= $1 AND "students"."deleted_at" IS NULL ORDER BY id`,
+			vars:   []any{since},
+		},
+		{
+			name:   "both bounds with deleted students and paging",
+			filter: types.StudentFilter{IncludeDeleted: true, CreatedAfter: since, UpdatedSince: since.Add(time.Hour), Limit: 10, Offset: 20},
+			sql:    `SELECT * FROM "students" WHERE created_at > $1 AND updated_at >= $2 ORDER BY id LIMIT $3 OFFSET $4`,
+			vars:   []any{since, since.Add(time.Hour), 10, 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var students []types.Student
+			stmt := p.studentsQuery(context.Background(), tt.filter).Order("id").Find(&students).Statement
+
+			if got := stmt.SQL.String(); got != tt.sql {
+				t.Fatalf("SQL = %s, want %s", got, tt.sql)
+			}
+			if !slices.Equal(stmt.Vars, tt.vars) {
+				t.Fatalf("vars = %v, want %v", stmt.Vars, tt.vars)
+			}
+		})
+	}
+}
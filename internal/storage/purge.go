@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunPurge permanently removes soft-deleted students once they have been
+// deleted for longer than retention, along with expired idempotency records,
+// checking every interval until ctx is done. A non-positive interval disables
+// purging.
+func RunPurge(ctx context.Context, s Storage, retention, interval time.Duration) {
+	if interval <= 0 {
+		slog.Info("Purging of deleted students is disabled", slog.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeDeletedStudents(ctx, time.Now().UTC().Add(-retention))
+		if err != nil {
+			slog.Error("Failed to purge deleted students", slog.String("error", err.Error()))
+		} else if purged > 0 {
+			slog.Info("Purged deleted students", slog.Int64("count", purged))
+		}
+
+		expired, err := s.PurgeExpiredIdempotencyRecords(ctx, time.Now().UTC())
+		if err != nil {
+			slog.Error("Failed to purge idempotency records", slog.String("error", err.Error()))
+		} else if expired > 0 {
+			slog.Info("Purged expired idempotency records", slog.Int64("count", expired))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// purgeCounter counts purge runs; every other storage method is left nil.
+type purgeCounter struct {
+	Storage
+	students, idempotency atomic.Int32
+}
+
+func (s *purgeCounter) PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error) {
+	s.students.Add(1)
+	return 0, nil
+}
+
+func (s *purgeCounter) PurgeExpiredIdempotencyRecords(ctx context.Context, before time.Time) (int64, error) {
+	s.idempotency.Add(1)
+	return 0, nil
+}
+
+func TestRunPurge(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		wantRuns bool
+	}{
+		{name: "zero interval disables purging", interval: 0},
+		{name: "negative interval disables purging", interval: -time.Minute},
+		{name: "positive interval purges", interval: time.Hour, wantRuns: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &purgeCounter{}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			// RunPurge returns once ctx is done, or at once when disabled.
+			RunPurge(ctx, store, time.Hour, tt.interval)
+
+			ran := store.students.Load() > 0 && store.idempotency.Load() > 0
+			if ran != tt.wantRuns {
+				t.Fatalf("purged %d and %d times, want runs: %v", store.students.Load(), store.idempotency.Load(), tt.wantRuns)
+			}
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// courseColumns selects a course with its Enrolled count, in which deleted
+// students no longer hold a seat.
+const courseColumns = "courses.id, courses.code, courses.title, courses.credits, courses.capacity, courses.created_at, courses.updated_at, " +
+	"(SELECT COUNT(*) FROM enrollments WHERE enrollments.course_id = courses.id AND enrollments.status = 'enrolled'" +
+	" AND enrollments.student_id IN (SELECT id FROM students WHERE deleted_at IS NULL))"
+
+const enrollmentColumns = "id, student_id, course_id, status, created_at, updated_at"
+
+func createCourseTables(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS courses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		code TEXT NOT NULL,
+		title TEXT NOT NULL,
+		credits REAL NOT NULL DEFAULT 0,
+		capacity INTEGER NOT NULL,
+		created_at DATETIME,
+		updated_at DATETIME
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_courses_code ON courses (code);
+	CREATE TABLE IF NOT EXISTS enrollments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		student_id INTEGER NOT NULL REFERENCES students (id),
+		course_id INTEGER NOT NULL REFERENCES courses (id),
+		status TEXT NOT NULL DEFAULT 'enrolled',
+		created_at DATETIME,
+		updated_at DATETIME
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_enrollments_student_course ON enrollments (student_id, course_id);
+	CREATE INDEX IF NOT EXISTS idx_enrollments_course_id ON enrollments (course_id);
+	CREATE TABLE IF NOT EXISTS grades (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		enrollment_id INTEGER NOT NULL REFERENCES enrollments (id),
+		term TEXT NOT NULL,
+		scale TEXT NOT NULL,
+		value TEXT NOT NULL,
+		letter TEXT NOT NULL,
+		points REAL NOT NULL,
+		created_at DATETIME,
+		updated_at DATETIME
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_grades_enrollment_id ON grades (enrollment_id)`)
+	return err
+}
+
+func scanCourse(row scanner) (types.Course, error) {
+	var course types.Course
+	err := row.Scan(&course.ID, &course.Code, &course.Title, &course.Credits, &course.Capacity, &course.CreatedAt, &course.UpdatedAt, &course.Enrolled)
+	return course, err
+}
+
+func scanEnrollment(row scanner) (types.Enrollment, error) {
+	var enrollment types.Enrollment
+	err := row.Scan(&enrollment.ID, &enrollment.StudentID, &enrollment.CourseID, &enrollment.Status, &enrollment.CreatedAt, &enrollment.UpdatedAt)
+	return enrollment, err
+}
+
+func (s *Sqlite) CreateCourse(ctx context.Context, course types.Course) (uint, error) {
+	now := time.Now().UTC()
+	res, err := s.DB.ExecContext(ctx, "INSERT INTO courses (code, title, credits, capacity, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		course.Code, course.Title, course.Credits, course.Capacity, now, now)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("course code %s already exists: %w", course.Code, storage.ErrConflict)
+		}
+		return 0, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(lastId), nil
+}
+
+func (s *Sqlite) GetCourse(ctx context.Context, id uint) (types.Course, error) {
+	return getCourse(ctx, s.DB, id)
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getCourse(ctx context.Context, db queryer, id uint) (types.Course, error) {
+	course, err := scanCourse(db.QueryRowContext(ctx, "SELECT "+courseColumns+" FROM courses WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Course{}, fmt.Errorf("course not found with id %d: %w", id, storage.ErrNotFound)
+		}
+		return types.Course{}, fmt.Errorf("query error: %w", err)
+	}
+	return course, nil
+}
+
+func (s *Sqlite) GetCourses(ctx context.Context) ([]types.Course, error) {
+	rows, err := s.DB.QueryContext(ctx, "SELECT "+courseColumns+" FROM courses ORDER BY code")
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var courses []types.Course
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, course)
+	}
+	return courses, rows.Err()
+}
+
+// lockCourse starts the write half of a transaction on a course by touching
+// its row. SQLite has no row locks; the write takes the database's write lock
+// up front, so concurrent capacity checks wait for this transaction instead
+// of reading the same count.
+func lockCourse(ctx context.Context, tx *sql.Tx, id uint) (types.Course, error) {
+	res, err := tx.ExecContext(ctx, "UPDATE courses SET updated_at = updated_at WHERE id = ?", id)
+	if err != nil {
+		return types.Course{}, err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return types.Course{}, err
+	} else if affected == 0 {
+		return types.Course{}, fmt.Errorf("course not found with id %d: %w", id, storage.ErrNotFound)
+	}
+	return getCourse(ctx, tx, id)
+}
+
+// reserveSeat fails with ErrConflict when course has no seat left.
+func reserveSeat(course types.Course) error {
+	if course.Enrolled >= course.Capacity {
+		return fmt.Errorf("course %s is full: %w", course.Code, storage.ErrConflict)
+	}
+	return nil
+}
+
+func (s *Sqlite) UpdateCourse(ctx context.Context, course types.Course) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := lockCourse(ctx, tx, course.ID)
+	if err != nil {
+		return err
+	}
+	if course.Capacity < current.Enrolled {
+		return fmt.Errorf("capacity %d is below the %d students enrolled: %w", course.Capacity, current.Enrolled, storage.ErrConflict)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE courses SET code = ?, title = ?, credits = ?, capacity = ?, updated_at = ? WHERE id = ?",
+		course.Code, course.Title, course.Credits, course.Capacity, time.Now().UTC(), course.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("course code %s already exists: %w", course.Code, storage.ErrConflict)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Sqlite) DeleteCourse(ctx context.Context, id uint) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockCourse(ctx, tx, id); err != nil {
+		return err
+	}
+
+	var enrollments int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM enrollments WHERE course_id = ?", id).Scan(&enrollments); err != nil {
+		return err
+	}
+	if enrollments > 0 {
+		return fmt.Errorf("course %d has %d enrollments: %w", id, enrollments, storage.ErrConflict)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM courses WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Sqlite) CreateEnrollment(ctx context.Context, studentID uint, courseID uint) (uint, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	course, err := lockCourse(ctx, tx, courseID)
+	if err != nil {
+		return 0, err
+	}
+
+	var found uint
+	err = tx.QueryRowContext(ctx, "SELECT id FROM students WHERE id = ? AND deleted_at IS NULL", studentID).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("student not found with id %d: %w", studentID, storage.ErrNotFound)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	existing, err := scanEnrollment(tx.QueryRowContext(ctx,
+		"SELECT "+enrollmentColumns+" FROM enrollments WHERE student_id = ? AND course_id = ?", studentID, courseID))
+	switch {
+	case err == nil && existing.Status == types.EnrollmentStatusDropped:
+		// Re-enrolling after dropping reuses the enrollment.
+		if err := reserveSeat(course); err != nil {
+			return 0, err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE enrollments SET status = ?, updated_at = ? WHERE id = ?", types.EnrollmentStatusEnrolled, now, existing.ID)
+		if err != nil {
+			return 0, err
+		}
+		return existing.ID, tx.Commit()
+	case err == nil:
+		return 0, fmt.Errorf("student %d is already %s in course %s: %w", studentID, existing.Status, course.Code, storage.ErrConflict)
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, err
+	}
+
+	if err := reserveSeat(course); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO enrollments (student_id, course_id, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		studentID, courseID, types.EnrollmentStatusEnrolled, now, now)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("student %d is already in course %s: %w", studentID, course.Code, storage.ErrConflict)
+		}
+		return 0, err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(lastId), tx.Commit()
+}
+
+func (s *Sqlite) GetEnrollment(ctx context.Context, id uint) (types.Enrollment, error) {
+	enrollment, err := scanEnrollment(s.DB.QueryRowContext(ctx, "SELECT "+enrollmentColumns+" FROM enrollments WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Enrollment{}, fmt.Errorf("enrollment not found with id %d: %w", id, storage.ErrNotFound)
+		}
+		return types.Enrollment{}, fmt.Errorf("query error: %w", err)
+	}
+	return enrollment, nil
+}
+
+func (s *Sqlite) GetEnrollments(ctx context.Context, filter types.EnrollmentFilter) ([]types.Enrollment, error) {
+	query := "SELECT " + enrollmentColumns + " FROM enrollments WHERE 1 = 1"
+	var args []any
+	if filter.StudentID != 0 {
+		query += " AND student_id = ?"
+		args = append(args, filter.StudentID)
+	}
+	if filter.CourseID != 0 {
+		query += " AND course_id = ?"
+		args = append(args, filter.CourseID)
+	}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var enrollments []types.Enrollment
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	return enrollments, rows.Err()
+}
+
+func (s *Sqlite) UpdateEnrollmentStatus(ctx context.Context, id uint, status string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Writing first takes the write lock before the capacity is read.
+	res, err := tx.ExecContext(ctx, "UPDATE enrollments SET updated_at = ? WHERE id = ?", time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("enrollment not found with id %d: %w", id, storage.ErrNotFound)
+	}
+
+	enrollment, err := scanEnrollment(tx.QueryRowContext(ctx, "SELECT "+enrollmentColumns+" FROM enrollments WHERE id = ?", id))
+	if err != nil {
+		return err
+	}
+
+	if status == types.EnrollmentStatusEnrolled && enrollment.Status != types.EnrollmentStatusEnrolled {
+		course, err := getCourse(ctx, tx, enrollment.CourseID)
+		if err != nil {
+			return err
+		}
+		if err := reserveSeat(course); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE enrollments SET status = ? WHERE id = ?", status, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Sqlite) DeleteEnrollment(ctx context.Context, id uint) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM grades WHERE enrollment_id = ?", id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, "DELETE FROM enrollments WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("enrollment not found with id %d: %w", id, storage.ErrNotFound)
+	}
+	return tx.Commit()
+}
+
+func (s *Sqlite) GetStudentCourses(ctx context.Context, studentID uint) ([]types.StudentCourse, error) {
+	var found uint
+	err := s.DB.QueryRowContext(ctx, "SELECT id FROM students WHERE id = ? AND deleted_at IS NULL", studentID).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("student not found with id %d: %w", studentID, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `SELECT enrollments.id, enrollments.status, enrollments.created_at, `+courseColumns+`, `+gradeColumns+`
+		FROM enrollments JOIN courses ON courses.id = enrollments.course_id
+		LEFT JOIN grades ON grades.enrollment_id = enrollments.id
+		WHERE enrollments.student_id = ?
+		ORDER BY enrollments.created_at, enrollments.id`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	result := []types.StudentCourse{}
+	for rows.Next() {
+		var sc types.StudentCourse
+		var g nullGrade
+		c := &sc.Course
+		err := rows.Scan(&sc.EnrollmentID, &sc.Status, &sc.EnrolledAt,
+			&c.ID, &c.Code, &c.Title, &c.Credits, &c.Capacity, &c.CreatedAt, &c.UpdatedAt, &c.Enrolled,
+			&g.ID, &g.EnrollmentID, &g.Term, &g.Scale, &g.Value, &g.Letter, &g.Points, &g.CreatedAt, &g.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		sc.Grade = g.grade()
+		result = append(result, sc)
+	}
+	return result, rows.Err()
+}
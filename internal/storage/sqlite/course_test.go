@@ -0,0 +1,256 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+func newTestStore(t *testing.T) *Sqlite {
+	t.Helper()
+	s, err := New(&config.Config{StoragePath: filepath.Join(t.TempDir(), "students.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.DB.Close() })
+	return s
+}
+
+func createStudents(t *testing.T, s *Sqlite, n int) []uint {
+	t.Helper()
+	ids := make([]uint, n)
+	for i := range ids {
+		id, err := s.CreateStudent(context.Background(), fmt.Sprintf("Student %d", i), fmt.Sprintf("s%d@example.com", i), "hash", 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func TestCreateEnrollmentCapacityUnderConcurrency(t *testing.T) {
+	tests := []struct {
+		capacity int
+		students int
+	}{
+		{capacity: 1, students: 8},
+		{capacity: 3, students: 12},
+		{capacity: 10, students: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d seats for %d students", tt.capacity, tt.students), func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestStore(t)
+			courseID, err := s.CreateCourse(ctx, types.Course{Code: "CS101", Title: "Intro", Credits: 3, Capacity: tt.capacity})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				enrolled int
+				full     int
+			)
+			for _, studentID := range createStudents(t, s, tt.students) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := s.CreateEnrollment(ctx, studentID, courseID)
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						enrolled++
+					case errors.Is(err, storage.ErrConflict):
+						full++
+					default:
+						t.Errorf("CreateEnrollment(%d) = %v", studentID, err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if enrolled != tt.capacity || full != tt.students-tt.capacity {
+				t.Fatalf("%d enrolled and %d turned away, want %d and %d", enrolled, full, tt.capacity, tt.students-tt.capacity)
+			}
+			course, err := s.GetCourse(ctx, courseID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if course.Enrolled != tt.capacity {
+				t.Fatalf("course reports %d enrolled, want %d", course.Enrolled, tt.capacity)
+			}
+		})
+	}
+}
+
+func TestEnrollmentSeats(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// free runs against a full one-seat course holding the first student.
+		free    func(s *Sqlite, studentID, enrollmentID uint) error
+		wantErr error
+		// restore brings the first student back once the second took the seat.
+		restore bool
+	}{
+		{
+			name:    "full course",
+			free:    func(s *Sqlite, studentID, enrollmentID uint) error { return nil },
+			wantErr: storage.ErrConflict,
+		},
+		{
+			name: "dropping frees the seat",
+			free: func(s *Sqlite, studentID, enrollmentID uint) error {
+				return s.UpdateEnrollmentStatus(ctx, enrollmentID, types.EnrollmentStatusDropped)
+			},
+		},
+		{
+			name: "completing frees the seat",
+			free: func(s *Sqlite, studentID, enrollmentID uint) error {
+				return s.UpdateEnrollmentStatus(ctx, enrollmentID, types.EnrollmentStatusCompleted)
+			},
+		},
+		{
+			name: "deleting the student frees the seat",
+			free: func(s *Sqlite, studentID, enrollmentID uint) error {
+				return s.DeleteStudent(ctx, studentID)
+			},
+		},
+		{
+			name: "restoring the student leaves the seat taken",
+			free: func(s *Sqlite, studentID, enrollmentID uint) error {
+				return s.DeleteStudent(ctx, studentID)
+			},
+			restore: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			ids := createStudents(t, s, 2)
+			courseID, err := s.CreateCourse(ctx, types.Course{Code: "CS101", Title: "Intro", Capacity: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			enrollmentID, err := s.CreateEnrollment(ctx, ids[0], courseID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.free(s, ids[0], enrollmentID); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = s.CreateEnrollment(ctx, ids[1], courseID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateEnrollment() = %v, want %v", err, tt.wantErr)
+			}
+			if !tt.restore {
+				return
+			}
+
+			if err := s.RestoreStudent(ctx, ids[0]); err != nil {
+				t.Fatal(err)
+			}
+			course, err := s.GetCourse(ctx, courseID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			enrollment, err := s.GetEnrollment(ctx, enrollmentID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if course.Enrolled != 1 || enrollment.Status != types.EnrollmentStatusDropped {
+				t.Fatalf("after restoring, %d enrolled and the restored student is %s, want 1 and dropped", course.Enrolled, enrollment.Status)
+			}
+		})
+	}
+}
+
+func TestReenrollAfterDropChecksCapacity(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	ids := createStudents(t, s, 2)
+	courseID, err := s.CreateCourse(ctx, types.Course{Code: "CS101", Title: "Intro", Capacity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := s.CreateEnrollment(ctx, ids[0], courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateEnrollmentStatus(ctx, first, types.EnrollmentStatusDropped); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateEnrollment(ctx, ids[1], courseID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateEnrollment(ctx, ids[0], courseID); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("re-enrolling into a full course = %v, want ErrConflict", err)
+	}
+	if err := s.UpdateEnrollmentStatus(ctx, first, types.EnrollmentStatusEnrolled); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("reopening into a full course = %v, want ErrConflict", err)
+	}
+}
+
+func TestPurgeDeletedStudentsRemovesEnrollments(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	ids := createStudents(t, s, 2)
+	courseID, err := s.CreateCourse(ctx, types.Course{Code: "CS101", Title: "Intro", Credits: 3, Capacity: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range ids {
+		enrollmentID, err := s.CreateEnrollment(ctx, id, courseID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.SetGrade(ctx, types.Grade{EnrollmentID: enrollmentID, Term: "Fall", Scale: "letter", Value: "A", Letter: "A", Points: 4}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.DeleteStudent(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	purged, err := s.PurgeDeletedStudents(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Fatalf("purged %d students, want 1", purged)
+	}
+
+	for table, want := range map[string]int{"enrollments": 1, "grades": 1} {
+		var count int
+		if err := s.DB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Errorf("%s has %d rows after the purge, want %d", table, count, want)
+		}
+	}
+
+	// The surviving student's enrollment still holds the course.
+	if err := s.DeleteCourse(ctx, courseID); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("DeleteCourse() with a remaining enrollment = %v, want ErrConflict", err)
+	}
+}
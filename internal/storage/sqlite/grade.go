@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+const gradeColumns = "grades.id, grades.enrollment_id, grades.term, grades.scale, grades.value, grades.letter, grades.points, grades.created_at, grades.updated_at"
+
+func scanGrade(row scanner) (types.Grade, error) {
+	var grade types.Grade
+	err := row.Scan(&grade.ID, &grade.EnrollmentID, &grade.Term, &grade.Scale, &grade.Value, &grade.Letter, &grade.Points, &grade.CreatedAt, &grade.UpdatedAt)
+	return grade, err
+}
+
+// nullGrade scans the gradeColumns of a LEFT JOIN, which are all NULL when
+// no grade was recorded.
+type nullGrade struct {
+	ID           sql.NullInt64
+	EnrollmentID sql.NullInt64
+	Term         sql.NullString
+	Scale        sql.NullString
+	Value        sql.NullString
+	Letter       sql.NullString
+	Points       sql.NullFloat64
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+}
+
+func (g nullGrade) grade() *types.Grade {
+	if !g.ID.Valid {
+		return nil
+	}
+	return &types.Grade{
+		ID:           uint(g.ID.Int64),
+		EnrollmentID: uint(g.EnrollmentID.Int64),
+		Term:         g.Term.String,
+		Scale:        g.Scale.String,
+		Value:        g.Value.String,
+		Letter:       g.Letter.String,
+		Points:       g.Points.Float64,
+		CreatedAt:    g.CreatedAt.Time,
+		UpdatedAt:    g.UpdatedAt.Time,
+	}
+}
+
+func (s *Sqlite) SetGrade(ctx context.Context, grade types.Grade) (types.Grade, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return types.Grade{}, err
+	}
+	defer tx.Rollback()
+
+	// Writing first takes the write lock before the status is read.
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, "UPDATE enrollments SET updated_at = ? WHERE id = ?", now, grade.EnrollmentID)
+	if err != nil {
+		return types.Grade{}, err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return types.Grade{}, err
+	} else if affected == 0 {
+		return types.Grade{}, fmt.Errorf("enrollment not found with id %d: %w", grade.EnrollmentID, storage.ErrNotFound)
+	}
+
+	var status string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM enrollments WHERE id = ?", grade.EnrollmentID).Scan(&status); err != nil {
+		return types.Grade{}, err
+	}
+	if status == types.EnrollmentStatusDropped {
+		return types.Grade{}, fmt.Errorf("enrollment %d was dropped: %w", grade.EnrollmentID, storage.ErrConflict)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO grades (enrollment_id, term, scale, value, letter, points, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (enrollment_id) DO UPDATE SET
+			term = excluded.term, scale = excluded.scale, value = excluded.value,
+			letter = excluded.letter, points = excluded.points, updated_at = excluded.updated_at`,
+		grade.EnrollmentID, grade.Term, grade.Scale, grade.Value, grade.Letter, grade.Points, now, now)
+	if err != nil {
+		return types.Grade{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE enrollments SET status = ? WHERE id = ?", types.EnrollmentStatusCompleted, grade.EnrollmentID)
+	if err != nil {
+		return types.Grade{}, err
+	}
+
+	saved, err := scanGrade(tx.QueryRowContext(ctx, "SELECT "+gradeColumns+" FROM grades WHERE enrollment_id = ?", grade.EnrollmentID))
+	if err != nil {
+		return types.Grade{}, err
+	}
+	return saved, tx.Commit()
+}
+
+func (s *Sqlite) GetGrade(ctx context.Context, enrollmentID uint) (types.Grade, error) {
+	grade, err := scanGrade(s.DB.QueryRowContext(ctx, "SELECT "+gradeColumns+" FROM grades WHERE enrollment_id = ?", enrollmentID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Grade{}, fmt.Errorf("no grade recorded for enrollment %d: %w", enrollmentID, storage.ErrNotFound)
+		}
+		return types.Grade{}, fmt.Errorf("query error: %w", err)
+	}
+	return grade, nil
+}
+
+func (s *Sqlite) DeleteGrade(ctx context.Context, enrollmentID uint) error {
+	res, err := s.DB.ExecContext(ctx, "DELETE FROM grades WHERE enrollment_id = ?", enrollmentID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("no grade recorded for enrollment %d: %w", enrollmentID, storage.ErrNotFound)
+	}
+	return nil
+}
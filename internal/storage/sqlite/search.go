@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+// searchColumns are studentColumns qualified for a join with students_fts,
+// whose name and email columns would otherwise be ambiguous.
+const searchColumns = "students.id, students.name, students.email, students.password, students.age, students.role, students.created_at, students.updated_at, students.deleted_at"
+
+// createSearchIndex maintains students_fts, an FTS5 index of student names
+// and emails kept in sync by triggers. The trigram tokenizer lets a query
+// match words it only shares some three-letter sequences with, which is what
+// makes search tolerate typos. It reports false when the driver was built
+// without FTS5 (the sqlite_fts5 build tag), in which case search falls back
+// to scanning the table.
+func createSearchIndex(db *sql.DB) (bool, error) {
+	var existing int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'students_fts'").Scan(&existing); err != nil {
+		return false, err
+	}
+
+	_, err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS students_fts USING fts5(
+		name, email, content='students', content_rowid='id', tokenize='trigram'
+	)`)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err = db.Exec(`CREATE TRIGGER IF NOT EXISTS students_fts_insert AFTER INSERT ON students BEGIN
+		INSERT INTO students_fts (rowid, name, email) VALUES (new.id, new.name, new.email);
+	END;
+	CREATE TRIGGER IF NOT EXISTS students_fts_delete AFTER DELETE ON students BEGIN
+		INSERT INTO students_fts (students_fts, rowid, name, email) VALUES ('delete', old.id, old.name, old.email);
+	END;
+	CREATE TRIGGER IF NOT EXISTS students_fts_update AFTER UPDATE OF name, email ON students BEGIN
+		INSERT INTO students_fts (students_fts, rowid, name, email) VALUES ('delete', old.id, old.name, old.email);
+		INSERT INTO students_fts (rowid, name, email) VALUES (new.id, new.name, new.email);
+	END`)
+	if err != nil {
+		return false, err
+	}
+
+	// Students created before the index existed are indexed once.
+	if existing == 0 {
+		if _, err := db.Exec("INSERT INTO students_fts (students_fts) VALUES ('rebuild')"); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (s *Sqlite) SearchStudents(ctx context.Context, search types.StudentSearch) ([]types.StudentMatch, error) {
+	grams := trigrams(search.Query)
+	if len(grams) == 0 {
+		return nil, nil
+	}
+	if !s.fullText {
+		return s.searchByTrigrams(ctx, search, grams)
+	}
+
+	quoted := make([]string, len(grams))
+	for i, trigram := range grams {
+		quoted[i] = `"` + strings.ReplaceAll(trigram, `"`, `""`) + `"`
+	}
+	// ORing the trigrams ranks rows sharing more of them higher, and a
+	// misspelt word still matches.
+	match := strings.Join(quoted, " OR ")
+
+	conditions, args := studentsConditions(search.StudentFilter)
+	page, pageArgs := pageClause(search.StudentFilter)
+
+	// bm25 is lower for better matches; names weigh twice as much as emails.
+	query := "SELECT " + searchColumns + ", -bm25(students_fts, 2.0, 1.0) AS score" +
+		" FROM students_fts JOIN students ON students.id = students_fts.rowid" +
+		" WHERE students_fts MATCH ?" + conditions +
+		" ORDER BY score DESC, students.id" + page
+
+	return s.queryMatches(ctx, query, append(append([]any{match}, args...), pageArgs...))
+}
+
+// searchByTrigrams ranks students by how many of the query's trigrams their
+// name and email contain, names again counting twice, for drivers built
+// without FTS5. It has no index to use, so every student is scanned.
+func (s *Sqlite) searchByTrigrams(ctx context.Context, search types.StudentSearch, grams []string) ([]types.StudentMatch, error) {
+	terms := make([]string, 0, len(grams))
+	var scoreArgs []any
+	for _, trigram := range grams {
+		terms = append(terms, "2 * (instr(lower(students.name), ?) > 0) + (instr(lower(students.email), ?) > 0)")
+		scoreArgs = append(scoreArgs, trigram, trigram)
+	}
+
+	conditions, args := studentsConditions(search.StudentFilter)
+	page, pageArgs := pageClause(search.StudentFilter)
+
+	query := "SELECT * FROM (SELECT " + searchColumns + ", " + strings.Join(terms, " + ") + " AS score" +
+		" FROM students WHERE 1 = 1" + conditions + ")" +
+		" WHERE score > 0 ORDER BY score DESC, id" + page
+
+	return s.queryMatches(ctx, query, append(append(scoreArgs, args...), pageArgs...))
+}
+
+func (s *Sqlite) queryMatches(ctx context.Context, query string, args []any) ([]types.StudentMatch, error) {
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []types.StudentMatch
+	for rows.Next() {
+		var m types.StudentMatch
+		err := rows.Scan(&m.Student.ID, &m.Student.Name, &m.Student.Email, &m.Student.Password, &m.Student.Age,
+			&m.Student.Role, &m.Student.CreatedAt, &m.Student.UpdatedAt, &m.Student.DeletedAt, &m.Score)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// trigrams returns the distinct three-character sequences of every word in
+// query, lower-cased. Words shorter than three characters have none and are
+// ignored.
+func trigrams(query string) []string {
+	var grams []string
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		runes := []rune(word)
+		for i := 0; i+3 <= len(runes); i++ {
+			trigram := string(runes[i : i+3])
+			if seen[trigram] {
+				continue
+			}
+			seen[trigram] = true
+			grams = append(grams, trigram)
+		}
+	}
+	return grams
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+func TestTrigrams(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: nil},
+		{query: "al bo", want: nil},
+		{query: "Ada", want: []string{"ada"}},
+		{query: "Lovelace", want: []string{"lov", "ove", "vel", "ela", "lac", "ace"}},
+		{query: "anna  ANNA", want: []string{"ann", "nna"}},
+		{query: "Zoë", want: []string{"zoë"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := trigrams(tt.query); !slices.Equal(got, tt.want) {
+				t.Fatalf("trigrams(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchStudents(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	people := []struct{ name, email string }{
+		{"Ada Lovelace", "ada@example.com"},
+		{"Edsger Dijkstra", "edsger@example.com"},
+		{"Alan Turing", "lovelace.fan@example.com"},
+		{"Deleted Lovelace", "gone@example.com"},
+	}
+	ids := make([]uint, len(people))
+	for i, p := range people {
+		id, err := s.CreateStudent(ctx, p.name, p.email, "hash", 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+	if err := s.DeleteStudent(ctx, ids[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		search types.StudentSearch
+		want   []uint
+	}{
+		{name: "too short", search: types.StudentSearch{Query: "ad"}},
+		{name: "no match", search: types.StudentSearch{Query: "xyzzy"}},
+		{name: "exact name", search: types.StudentSearch{Query: "Dijkstra"}, want: []uint{ids[1]}},
+		{name: "typo", search: types.StudentSearch{Query: "Dijkstar"}, want: []uint{ids[1]}},
+		{name: "names outrank emails", search: types.StudentSearch{Query: "lovelace"}, want: []uint{ids[0], ids[2]}},
+		{
+			name:   "including deleted",
+			search: types.StudentSearch{Query: "lovelace", StudentFilter: types.StudentFilter{IncludeDeleted: true}},
+			want:   []uint{ids[0], ids[3], ids[2]},
+		},
+		{
+			name:   "paged",
+			search: types.StudentSearch{Query: "lovelace", StudentFilter: types.StudentFilter{Limit: 1, Offset: 1}},
+			want:   []uint{ids[2]},
+		},
+	}
+
+	// Default builds only have the fallback scan; -tags sqlite_fts5 also
+	// exercises the index.
+	modes := []bool{false}
+	if s.fullText {
+		modes = append(modes, true)
+	}
+
+	for _, fullText := range modes {
+		for _, tt := range tests {
+			name := tt.name + " by scan"
+			if fullText {
+				name = tt.name + " by index"
+			}
+			t.Run(name, func(t *testing.T) {
+				s.fullText = fullText
+				matches, err := s.SearchStudents(ctx, tt.search)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got := make([]uint, len(matches))
+				for i, m := range matches {
+					got[i] = m.Student.ID
+					if i > 0 && m.Score > matches[i-1].Score {
+						t.Errorf("match %d scores %g above %g", i, m.Score, matches[i-1].Score)
+					}
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Fatalf("matches = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
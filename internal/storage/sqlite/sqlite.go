@@ -1,17 +1,37 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
 	"github.com/Saidurbu/go-lang-crud/internal/types"
-	_ "github.com/mattn/go-sqlite3"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/mattn/go-sqlite3"
 )
 
+const studentColumns = "id, name, email, password, age, role, created_at, updated_at, deleted_at"
+
 type Sqlite struct {
 	DB *sql.DB
+	// fullText is false when the driver was built without FTS5.
+	fullText bool
+}
+
+var _ storage.Storage = (*Sqlite)(nil)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanStudent(row scanner) (types.Student, error) {
+	var student types.Student
+	err := row.Scan(&student.ID, &student.Name, &student.Email, &student.Password, &student.Age, &student.Role, &student.CreatedAt, &student.UpdatedAt, &student.DeletedAt)
+	return student, err
 }
 
 func New(cfg *config.Config) (*Sqlite, error) {
@@ -26,25 +46,139 @@ func New(cfg *config.Config) (*Sqlite, error) {
 		return nil, err
 	}
 
-	return &Sqlite{DB: db}, nil
+	if err := addColumnIfMissing(db, "students", "role", "TEXT NOT NULL DEFAULT 'student'"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "students", "deleted_at", "DATETIME"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "students", "created_at", "DATETIME"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "students", "updated_at", "DATETIME"); err != nil {
+		return nil, err
+	}
+
+	// SQLite cannot add a column with a non-constant default, so rows created
+	// before the timestamp columns existed are backfilled here instead.
+	_, err = db.Exec(`UPDATE students SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+		UPDATE students SET updated_at = created_at WHERE updated_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_students_deleted_at ON students (deleted_at);
+		CREATE INDEX IF NOT EXISTS idx_students_created_at ON students (created_at);
+		CREATE INDEX IF NOT EXISTS idx_students_updated_at ON students (updated_at);
+		DROP INDEX IF EXISTS idx_students_email_lower;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_students_email_active ON students (LOWER(email)) WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS audit_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		actor TEXT,
+		action TEXT,
+		target_id INTEGER,
+		changes TEXT,
+		request_id TEXT,
+		created_at DATETIME
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_entries_target_id ON audit_entries (target_id);
+		CREATE INDEX IF NOT EXISTS idx_audit_entries_actor ON audit_entries (actor);
+		CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries (created_at)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS idempotency_records (
+		owner TEXT NOT NULL,
+		idempotency_key TEXT NOT NULL,
+		fingerprint TEXT,
+		status_code INTEGER,
+		content_type TEXT,
+		body BLOB,
+		created_at DATETIME,
+		expires_at DATETIME,
+		PRIMARY KEY (owner, idempotency_key)
+	);
+	CREATE INDEX IF NOT EXISTS idx_idempotency_records_expires_at ON idempotency_records (expires_at)`)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := createCourseTables(db); err != nil {
+		return nil, err
+	}
+
+	fullText, err := createSearchIndex(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sqlite{DB: db, fullText: fullText}, nil
 }
 
-func (s *Sqlite) CreateStudent(name string, email string, password string, age int) (int64, error) {
-	if password == "" {
-		return 0, fmt.Errorf("password is required")
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// addColumnIfMissing adds a column to an existing table, since CREATE TABLE
+// IF NOT EXISTS leaves tables created by older versions untouched.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+func (s *Sqlite) CreateStudent(ctx context.Context, name string, email string, password string, age int) (uint, error) {
+	hashedPassword, err := storage.HashPassword(password)
 	if err != nil {
 		return 0, err
 	}
-	stmt, err := s.DB.Prepare("INSERT INTO students (name, email, password, age) VALUES (?, ?, ?, ?)")
+	stmt, err := s.DB.PrepareContext(ctx, "INSERT INTO students (name, email, password, age, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return 0, err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.Exec(name, email, string(hashedPassword), age)
+	now := time.Now().UTC()
+	res, err := stmt.ExecContext(ctx, name, email, hashedPassword, age, now, now)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("email %s already registered: %w", email, storage.ErrConflict)
+		}
 		return 0, err
 	}
 
@@ -53,21 +187,65 @@ func (s *Sqlite) CreateStudent(name string, email string, password string, age i
 		return 0, err
 	}
 
-	return lastId, nil
+	return uint(lastId), nil
 }
 
-func (s *Sqlite) GetStudentById(id int64) (types.Student, error) {
-	stmt, err := s.DB.Prepare("SELECT id, name, email, password, age FROM students WHERE id = ?")
+func (s *Sqlite) CreateStudents(ctx context.Context, students []types.Student) ([]uint, error) {
+	rows := make([]types.Student, len(students))
+	copy(rows, students)
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO students (name, email, password, age, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	ids := make([]uint, 0, len(rows))
+	for i, student := range rows {
+		res, err := stmt.ExecContext(ctx, student.Name, student.Email, student.Password, student.Age, now, now)
+		if err != nil {
+			if isUniqueViolation(err) {
+				err = fmt.Errorf("email %s already registered: %w", student.Email, storage.ErrConflict)
+			}
+			return nil, &storage.RowError{Index: i, Err: err}
+		}
+
+		lastId, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(lastId))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (s *Sqlite) GetStudentById(ctx context.Context, id uint, includeDeleted bool) (types.Student, error) {
+	query := "SELECT " + studentColumns + " FROM students WHERE id = ?"
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	stmt, err := s.DB.PrepareContext(ctx, query)
 	if err != nil {
 		return types.Student{}, err
 	}
 	defer stmt.Close()
 
-	var student types.Student
-	err = stmt.QueryRow(id).Scan(&student.ID, &student.Name, &student.Email, &student.Password, &student.Age)
+	student, err := scanStudent(stmt.QueryRowContext(ctx, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return types.Student{}, fmt.Errorf("student not found with id %d: %w", id, err)
+			return types.Student{}, fmt.Errorf("student not found with id %d: %w", id, storage.ErrNotFound)
 		}
 		return types.Student{}, fmt.Errorf("query error: %w", err)
 	}
@@ -75,31 +253,72 @@ func (s *Sqlite) GetStudentById(id int64) (types.Student, error) {
 	return student, nil
 }
 
-func (s *Sqlite) GetStudentByEmail(email string) (types.Student, error) {
-	stmt, err := s.DB.Prepare("SELECT id, name, email, password, age FROM students WHERE email = ?")
+func (s *Sqlite) GetStudentByEmail(ctx context.Context, email string) (types.Student, error) {
+	stmt, err := s.DB.PrepareContext(ctx, "SELECT "+studentColumns+" FROM students WHERE LOWER(email) = LOWER(?) AND deleted_at IS NULL")
 	if err != nil {
 		return types.Student{}, err
 	}
 	defer stmt.Close()
-	var student types.Student
-	err = stmt.QueryRow(email).Scan(&student.ID, &student.Name, &student.Email, &student.Password, &student.Age)
+	student, err := scanStudent(stmt.QueryRowContext(ctx, email))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return types.Student{}, fmt.Errorf("student not found with email %s: %w", email, err)
+			return types.Student{}, fmt.Errorf("student not found with email %s: %w", email, storage.ErrNotFound)
 		}
 		return types.Student{}, fmt.Errorf("query error: %w", err)
 	}
 	return student, nil
 }
 
-func (s *Sqlite) GetStudents() ([]types.Student, error) {
-	stmt, err := s.DB.Prepare("SELECT id, name, email, password, age FROM students")
+// studentsQuery returns a SELECT of the students matching filter and its arguments.
+func studentsQuery(filter types.StudentFilter) (string, []any) {
+	conditions, args := studentsConditions(filter)
+	query := "SELECT " + studentColumns + " FROM students WHERE 1 = 1" + conditions + " ORDER BY id"
+	page, pageArgs := pageClause(filter)
+	return query + page, append(args, pageArgs...)
+}
+
+// studentsConditions returns the AND clauses selecting the students matching
+// filter, without pagination.
+func studentsConditions(filter types.StudentFilter) (string, []any) {
+	var query string
+	var args []any
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query += " AND created_at > ?"
+		args = append(args, filter.CreatedAfter.UTC())
+	}
+	if !filter.UpdatedSince.IsZero() {
+		query += " AND updated_at >= ?"
+		args = append(args, filter.UpdatedSince.UTC())
+	}
+	return query, args
+}
+
+// pageClause returns the LIMIT and OFFSET for filter. SQLite needs a LIMIT
+// before an OFFSET, and -1 means none.
+func pageClause(filter types.StudentFilter) (string, []any) {
+	if filter.Limit <= 0 && filter.Offset <= 0 {
+		return "", nil
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	return " LIMIT ? OFFSET ?", []any{limit, filter.Offset}
+}
+
+func (s *Sqlite) GetStudents(ctx context.Context, filter types.StudentFilter) ([]types.Student, error) {
+	query, args := studentsQuery(filter)
+
+	stmt, err := s.DB.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query()
+	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
@@ -107,8 +326,7 @@ func (s *Sqlite) GetStudents() ([]types.Student, error) {
 
 	var students []types.Student
 	for rows.Next() {
-		var student types.Student
-		err = rows.Scan(&student.ID, &student.Name, &student.Email, &student.Password, &student.Age)
+		student, err := scanStudent(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -118,52 +336,298 @@ func (s *Sqlite) GetStudents() ([]types.Student, error) {
 	return students, nil
 }
 
-func (s *Sqlite) UpdateStudent(id int64, name string, email string, password string, age int) error {
+func (s *Sqlite) StreamStudents(ctx context.Context, filter types.StudentFilter, each func(types.Student) error) error {
+	query, args := studentsQuery(filter)
 
-	if password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		student, err := scanStudent(rows)
 		if err != nil {
 			return err
 		}
-
-		stmt, err := s.DB.Prepare("UPDATE students SET name = ?, email = ?, password = ?, age = ? WHERE id = ?")
-		if err != nil {
+		if err := each(student); err != nil {
 			return err
 		}
-		defer stmt.Close()
+	}
+	return rows.Err()
+}
 
-		_, err = stmt.Exec(name, email, string(hashedPassword), age, id)
+func (s *Sqlite) UpdateStudent(ctx context.Context, id uint, name string, email string, password string, age int) error {
+	query := "UPDATE students SET name = ?, email = ?, age = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL"
+	args := []any{name, email, age, time.Now().UTC(), id}
+
+	if password != "" {
+		hashedPassword, err := storage.HashPassword(password)
 		if err != nil {
 			return err
 		}
 
-	} else {
-		stmt, err := s.DB.Prepare("UPDATE students SET name = ?, email = ?, age = ? WHERE id = ?")
-		if err != nil {
-			return err
+		query = "UPDATE students SET name = ?, email = ?, password = ?, age = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL"
+		args = []any{name, email, hashedPassword, age, time.Now().UTC(), id}
+	}
+
+	stmt, err := s.DB.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("email %s already registered: %w", email, storage.ErrConflict)
 		}
-		defer stmt.Close()
-		_, err = stmt.Exec(name, email, age, id)
-		if err != nil {
-			return err
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("student not found with id %d: %w", id, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (s *Sqlite) DeleteStudent(ctx context.Context, id uint) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, "UPDATE students SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", now, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("student not found with id %d: %w", id, storage.ErrNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE enrollments SET status = ?, updated_at = ? WHERE student_id = ? AND status = ?",
+		types.EnrollmentStatusDropped, now, id, types.EnrollmentStatusEnrolled)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Sqlite) RestoreStudent(ctx context.Context, id uint) error {
+	stmt, err := s.DB.PrepareContext(ctx, "UPDATE students SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, time.Now().UTC(), id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("email of student %d has been registered again: %w", id, storage.ErrConflict)
 		}
+		return err
+	}
 
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
 	}
+	if affected == 0 {
+		return fmt.Errorf("deleted student not found with id %d: %w", id, storage.ErrNotFound)
+	}
+
 	return nil
+}
+
+// PurgeDeletedStudents removes students deleted before before together with
+// their enrollments and grades, which would otherwise be left orphaned.
+func (s *Sqlite) PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
+	before = before.UTC()
+	_, err = tx.ExecContext(ctx, `DELETE FROM grades WHERE enrollment_id IN (
+		SELECT enrollments.id FROM enrollments JOIN students ON students.id = enrollments.student_id
+		WHERE students.deleted_at IS NOT NULL AND students.deleted_at < ?)`, before)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM enrollments WHERE student_id IN (
+		SELECT id FROM students WHERE deleted_at IS NOT NULL AND deleted_at < ?)`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM students WHERE deleted_at IS NOT NULL AND deleted_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, tx.Commit()
 }
 
-func (s *Sqlite) DeleteStudent(id int64) error {
-	stmt, err := s.DB.Prepare("DELETE FROM students WHERE id = ?")
+func (s *Sqlite) CreateAuditEntry(ctx context.Context, entry types.AuditEntry) error {
+	stmt, err := s.DB.PrepareContext(ctx, "INSERT INTO audit_entries (actor, action, target_id, changes, request_id, created_at) VALUES (?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(id)
+	_, err = stmt.ExecContext(ctx, entry.Actor, entry.Action, entry.TargetID, string(entry.Changes), entry.RequestID, entry.CreatedAt.UTC())
+	return err
+}
+
+func (s *Sqlite) GetAuditEntries(ctx context.Context, filter types.AuditFilter) ([]types.AuditEntry, error) {
+	query := "SELECT id, actor, action, target_id, changes, request_id, created_at FROM audit_entries WHERE 1 = 1"
+	var args []any
+	if filter.TargetID != 0 {
+		query += " AND target_id = ?"
+		args = append(args, filter.TargetID)
+	}
+	if filter.Actor != "" {
+		query += " AND actor = ?"
+		args = append(args, filter.Actor)
+	}
+	if !filter.Since.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, filter.Since.UTC())
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []types.AuditEntry
+	for rows.Next() {
+		var (
+			entry   types.AuditEntry
+			changes string
+		)
+		if err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.TargetID, &changes, &entry.RequestID, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entry.Changes = json.RawMessage(changes)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *Sqlite) CreateIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	// An expired record no longer holds the key, so it is replaced.
+	_, err = tx.ExecContext(ctx, "DELETE FROM idempotency_records WHERE owner = ? AND idempotency_key = ? AND expires_at <= ?",
+		record.Owner, record.Key, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO idempotency_records (owner, idempotency_key, fingerprint, status_code, content_type, body, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		record.Owner, record.Key, record.Fingerprint, record.StatusCode, record.ContentType, record.Body, record.CreatedAt.UTC(), record.ExpiresAt.UTC())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("idempotency key %q is already in use: %w", record.Key, storage.ErrConflict)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Sqlite) GetIdempotencyRecord(ctx context.Context, owner string, key string) (types.IdempotencyRecord, error) {
+	var record types.IdempotencyRecord
+	err := s.DB.QueryRowContext(ctx, "SELECT owner, idempotency_key, fingerprint, status_code, content_type, body, created_at, expires_at FROM idempotency_records WHERE owner = ? AND idempotency_key = ? AND expires_at > ?",
+		owner, key, time.Now().UTC()).
+		Scan(&record.Owner, &record.Key, &record.Fingerprint, &record.StatusCode, &record.ContentType, &record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return types.IdempotencyRecord{}, fmt.Errorf("idempotency key %q not found: %w", key, storage.ErrNotFound)
+	}
+	if err != nil {
+		return types.IdempotencyRecord{}, fmt.Errorf("query error: %w", err)
+	}
+	return record, nil
+}
+
+func (s *Sqlite) CompleteIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error {
+	res, err := s.DB.ExecContext(ctx, "UPDATE idempotency_records SET status_code = ?, content_type = ?, body = ? WHERE owner = ? AND idempotency_key = ?",
+		record.StatusCode, record.ContentType, record.Body, record.Owner, record.Key)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("idempotency key %q not found: %w", record.Key, storage.ErrNotFound)
+	}
+	return nil
+}
+
+func (s *Sqlite) DeleteIdempotencyRecord(ctx context.Context, owner string, key string) error {
+	_, err := s.DB.ExecContext(ctx, "DELETE FROM idempotency_records WHERE owner = ? AND idempotency_key = ?", owner, key)
+	return err
+}
+
+func (s *Sqlite) PurgeExpiredIdempotencyRecords(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.DB.ExecContext(ctx, "DELETE FROM idempotency_records WHERE expires_at <= ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *Sqlite) DBStats() sql.DBStats {
+	return s.DB.Stats()
+}
+
+func (s *Sqlite) Ping(ctx context.Context) error {
+	return s.DB.PingContext(ctx)
+}
+
+func (s *Sqlite) CheckMigrations(ctx context.Context) error {
+	names := []string{"students", "audit_entries", "idempotency_records", "courses", "enrollments", "grades", "idx_students_email_active"}
+	if s.fullText {
+		names = append(names, "students_fts")
+	}
+	for _, name := range names {
+		var found string
+		err := s.DB.QueryRowContext(ctx, "SELECT name FROM sqlite_master WHERE name = ?", name).Scan(&found)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%s is missing", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	ids := createStudents(t, s, 2)
+
+	if err := s.DeleteStudent(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{name: "hidden by default", run: func() error { _, err := s.GetStudentById(ctx, ids[0], false); return err }, want: storage.ErrNotFound},
+		{name: "visible when asked", run: func() error { _, err := s.GetStudentById(ctx, ids[0], true); return err }},
+		{name: "deleted twice", run: func() error { return s.DeleteStudent(ctx, ids[0]) }, want: storage.ErrNotFound},
+		{name: "updated while deleted", run: func() error { return s.UpdateStudent(ctx, ids[0], "x", "x@example.com", "", 1) }, want: storage.ErrNotFound},
+		{name: "restoring a live student", run: func() error { return s.RestoreStudent(ctx, ids[1]) }, want: storage.ErrNotFound},
+		{name: "restoring an unknown student", run: func() error { return s.RestoreStudent(ctx, 999) }, want: storage.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		filter types.StudentFilter
+		want   int
+	}{
+		{filter: types.StudentFilter{}, want: 1},
+		{filter: types.StudentFilter{IncludeDeleted: true}, want: 2},
+	} {
+		students, err := s.GetStudents(ctx, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(students) != tt.want {
+			t.Errorf("GetStudents(%+v) returned %d students, want %d", tt.filter, len(students), tt.want)
+		}
+	}
+
+	if err := s.RestoreStudent(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if student, err := s.GetStudentById(ctx, ids[0], false); err != nil || student.DeletedAt.Valid {
+		t.Fatalf("restored student = %+v, %v", student, err)
+	}
+}
+
+func TestPurgeDeletedStudents(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	ids := createStudents(t, s, 3)
+	for _, id := range ids[:2] {
+		if err := s.DeleteStudent(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if purged, err := s.PurgeDeletedStudents(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Fatalf("purging students deleted over an hour ago = %d, %v, want 0", purged, err)
+	}
+	if purged, err := s.PurgeDeletedStudents(ctx, time.Now().Add(time.Minute)); err != nil || purged != 2 {
+		t.Fatalf("purging every deleted student = %d, %v, want 2", purged, err)
+	}
+	if _, err := s.GetStudentById(ctx, ids[0], true); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("purged student = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetStudentById(ctx, ids[2], false); err != nil {
+		t.Fatalf("live student = %v, want it kept", err)
+	}
+}
+
+func TestStudentTimeFilters(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	ids := createStudents(t, s, 3)
+
+	base := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range ids {
+		created := base.Add(time.Duration(i) * time.Hour)
+		updated := created.Add(24 * time.Hour)
+		if _, err := s.DB.Exec("UPDATE students SET created_at = ?, updated_at = ? WHERE id = ?", created, updated, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter types.StudentFilter
+		want   []uint
+	}{
+		{name: "no bounds", want: ids},
+		{name: "created after is exclusive", filter: types.StudentFilter{CreatedAfter: base}, want: ids[1:]},
+		{name: "created after between rows", filter: types.StudentFilter{CreatedAfter: base.Add(90 * time.Minute)}, want: ids[2:]},
+		{name: "created after the last row", filter: types.StudentFilter{CreatedAfter: base.Add(2 * time.Hour)}},
+		{name: "created after in another zone", filter: types.StudentFilter{CreatedAfter: base.In(time.FixedZone("CEST", 2*60*60))}, want: ids[1:]},
+		{name: "updated since is inclusive", filter: types.StudentFilter{UpdatedSince: base.Add(25 * time.Hour)}, want: ids[1:]},
+		{name: "updated since after every row", filter: types.StudentFilter{UpdatedSince: base.Add(27 * time.Hour)}},
+		{
+			name:   "both bounds",
+			filter: types.StudentFilter{CreatedAfter: base, UpdatedSince: base.Add(26 * time.Hour)},
+			want:   ids[2:],
+		},
+		{name: "bounds with paging", filter: types.StudentFilter{CreatedAfter: base, Limit: 1}, want: ids[1:2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			students, err := s.GetStudents(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uint
+			for _, student := range students {
+				got = append(got, student.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("GetStudents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailUniqueness(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func(s *Sqlite, ada uint) error
+		want error
+	}{
+		{
+			name: "same email",
+			run: func(s *Sqlite, ada uint) error {
+				_, err := s.CreateStudent(ctx, "Ada", "ada@example.com", "pw", 20)
+				return err
+			},
+			want: storage.ErrConflict,
+		},
+		{
+			name: "differs only in case",
+			run: func(s *Sqlite, ada uint) error {
+				_, err := s.CreateStudent(ctx, "Ada", "ADA@Example.com", "pw", 20)
+				return err
+			},
+			want: storage.ErrConflict,
+		},
+		{
+			name: "updated to a taken email",
+			run: func(s *Sqlite, ada uint) error {
+				id, err := s.CreateStudent(ctx, "Grace", "grace@example.com", "pw", 20)
+				if err != nil {
+					return err
+				}
+				return s.UpdateStudent(ctx, id, "Grace", "Ada@example.com", "", 20)
+			},
+			want: storage.ErrConflict,
+		},
+		{
+			name: "bulk insert",
+			run: func(s *Sqlite, ada uint) error {
+				_, err := s.CreateStudents(ctx, []types.Student{{Name: "Lin", Email: "lin@example.com"}, {Name: "Ada", Email: "ada@EXAMPLE.com"}})
+				var rowErr *storage.RowError
+				if errors.As(err, &rowErr) && rowErr.Index != 1 {
+					t.Errorf("row error index = %d, want 1", rowErr.Index)
+				}
+				return err
+			},
+			want: storage.ErrConflict,
+		},
+		{
+			name: "reused after deletion",
+			run: func(s *Sqlite, ada uint) error {
+				if err := s.DeleteStudent(ctx, ada); err != nil {
+					return err
+				}
+				_, err := s.CreateStudent(ctx, "Ada", "ada@example.com", "pw", 20)
+				return err
+			},
+		},
+		{
+			name: "restored after reuse",
+			run: func(s *Sqlite, ada uint) error {
+				if err := s.DeleteStudent(ctx, ada); err != nil {
+					return err
+				}
+				if _, err := s.CreateStudent(ctx, "Ada", "Ada@example.com", "pw", 20); err != nil {
+					return err
+				}
+				return s.RestoreStudent(ctx, ada)
+			},
+			want: storage.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			ada, err := s.CreateStudent(ctx, "Ada", "ada@example.com", "pw", 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.run(s, ada); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		drop    string
+		wantErr string
+	}{
+		{name: "migrated"},
+		{name: "missing table", drop: "DROP TABLE grades", wantErr: "grades is missing"},
+		{name: "missing index", drop: "DROP INDEX idx_students_email_active", wantErr: "idx_students_email_active is missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestStore(t)
+			if tt.drop != "" {
+				if _, err := s.DB.Exec(tt.drop); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := s.Ping(ctx); err != nil {
+				t.Fatal(err)
+			}
+			err := s.CheckMigrations(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckMigrations() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("CheckMigrations() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,12 +1,77 @@
 package storage
 
-import "github.com/Saidurbu/go-lang-crud/internal/types"
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+)
 
 type Storage interface {
-	CreateStudent(name string, email string, password string, age int) (uint, error)
-	GetStudentById(id uint) (types.Student, error)
-	GetStudents() ([]types.Student, error)
-	UpdateStudent(id uint, name string, email string, password string, age int) error
-	DeleteStudent(id uint) error
-	GetStudentByEmail(email string) (types.Student, error)
+	CreateStudent(ctx context.Context, name string, email string, password string, age int) (uint, error)
+	// CreateStudents inserts students, whose passwords must already be
+	// hashed by HashPasswords, in a single transaction and returns their IDs
+	// in order. If any row fails nothing is inserted and the error is a
+	// *RowError naming that row.
+	CreateStudents(ctx context.Context, students []types.Student) ([]uint, error)
+	GetStudentById(ctx context.Context, id uint, includeDeleted bool) (types.Student, error)
+	GetStudents(ctx context.Context, filter types.StudentFilter) ([]types.Student, error)
+	// StreamStudents calls each for every student matching filter, in ID
+	// order, reading rows through a cursor instead of loading them all. It
+	// stops at and returns the first error from each.
+	StreamStudents(ctx context.Context, filter types.StudentFilter, each func(types.Student) error) error
+	// SearchStudents ranks students whose name or email matches search.Query,
+	// tolerating typos, best match first.
+	SearchStudents(ctx context.Context, search types.StudentSearch) ([]types.StudentMatch, error)
+	UpdateStudent(ctx context.Context, id uint, name string, email string, password string, age int) error
+	// DeleteStudent soft deletes a student and drops their active
+	// enrollments, so the seats they free stay with whoever takes them and
+	// RestoreStudent never brings a course over capacity.
+	DeleteStudent(ctx context.Context, id uint) error
+	RestoreStudent(ctx context.Context, id uint) error
+	PurgeDeletedStudents(ctx context.Context, before time.Time) (int64, error)
+	GetStudentByEmail(ctx context.Context, email string) (types.Student, error)
+	CreateCourse(ctx context.Context, course types.Course) (uint, error)
+	GetCourse(ctx context.Context, id uint) (types.Course, error)
+	GetCourses(ctx context.Context) ([]types.Course, error)
+	// UpdateCourse fails with ErrConflict when the new capacity is below the
+	// number of students enrolled.
+	UpdateCourse(ctx context.Context, course types.Course) error
+	// DeleteCourse fails with ErrConflict while the course has enrollments.
+	DeleteCourse(ctx context.Context, id uint) error
+	// CreateEnrollment enrolls a student in a course, checking capacity in the
+	// same transaction so that concurrent enrollments cannot overfill it. A
+	// full course and an existing enrollment are reported as ErrConflict.
+	CreateEnrollment(ctx context.Context, studentID uint, courseID uint) (uint, error)
+	GetEnrollment(ctx context.Context, id uint) (types.Enrollment, error)
+	GetEnrollments(ctx context.Context, filter types.EnrollmentFilter) ([]types.Enrollment, error)
+	// UpdateEnrollmentStatus checks capacity like CreateEnrollment when the
+	// enrollment takes up a seat again.
+	UpdateEnrollmentStatus(ctx context.Context, id uint, status string) error
+	DeleteEnrollment(ctx context.Context, id uint) error
+	GetStudentCourses(ctx context.Context, studentID uint) ([]types.StudentCourse, error)
+	// SetGrade records or replaces the grade of grade.EnrollmentID and marks
+	// the enrollment completed. Dropped enrollments cannot be graded and are
+	// reported as ErrConflict.
+	SetGrade(ctx context.Context, grade types.Grade) (types.Grade, error)
+	GetGrade(ctx context.Context, enrollmentID uint) (types.Grade, error)
+	// DeleteGrade removes a grade, leaving the enrollment completed.
+	DeleteGrade(ctx context.Context, enrollmentID uint) error
+	CreateAuditEntry(ctx context.Context, entry types.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter types.AuditFilter) ([]types.AuditEntry, error)
+	// CreateIdempotencyRecord reserves record.Key for record.Owner. It fails
+	// with ErrConflict while an unexpired record for the same key exists.
+	CreateIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error
+	// GetIdempotencyRecord returns ErrNotFound for unknown and expired keys.
+	GetIdempotencyRecord(ctx context.Context, owner string, key string) (types.IdempotencyRecord, error)
+	// CompleteIdempotencyRecord stores the response recorded for a reserved key.
+	CompleteIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) error
+	DeleteIdempotencyRecord(ctx context.Context, owner string, key string) error
+	PurgeExpiredIdempotencyRecords(ctx context.Context, before time.Time) (int64, error)
+	DBStats() sql.DBStats
+	Ping(ctx context.Context) error
+	// CheckMigrations reports an error when the schema is missing tables or
+	// columns this version of the service relies on.
+	CheckMigrations(ctx context.Context) error
 }
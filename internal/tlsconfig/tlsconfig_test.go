@@ -0,0 +1,208 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+)
+
+// writeCert writes a self-signed certificate for commonName and its key to
+// dir, returning their paths.
+func writeCert(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "server")
+	emptyCA := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(emptyCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	base := config.TLS{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2", ClientAuth: "none"}
+	with := func(modify func(*config.TLS)) config.TLS {
+		cfg := base
+		modify(&cfg)
+		return cfg
+	}
+
+	tests := []struct {
+		name       string
+		cfg        config.TLS
+		minVersion uint16
+		clientAuth tls.ClientAuthType
+		suites     []uint16
+		wantErr    string
+	}{
+		{name: "defaults", cfg: base, minVersion: tls.VersionTLS12, clientAuth: tls.NoClientCert},
+		{name: "tls 1.3", cfg: with(func(c *config.TLS) { c.MinVersion = "1.3" }), minVersion: tls.VersionTLS13, clientAuth: tls.NoClientCert},
+		{
+			name: "cipher suites",
+			cfg: with(func(c *config.TLS) {
+				c.CipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", " TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}
+			}),
+			minVersion: tls.VersionTLS12,
+			clientAuth: tls.NoClientCert,
+			suites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+		},
+		{
+			name:       "verified client certificates",
+			cfg:        with(func(c *config.TLS) { c.ClientAuth = "require_and_verify"; c.ClientCAFile = certFile }),
+			minVersion: tls.VersionTLS12,
+			clientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{name: "unknown version", cfg: with(func(c *config.TLS) { c.MinVersion = "2.0" }), wantErr: `unsupported tls min_version "2.0"`},
+		{name: "unknown client auth", cfg: with(func(c *config.TLS) { c.ClientAuth = "maybe" }), wantErr: `unsupported tls client_auth "maybe"`},
+		{name: "insecure suite", cfg: with(func(c *config.TLS) { c.CipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"} }), wantErr: "unknown or insecure tls cipher suite"},
+		{name: "missing client CA", cfg: with(func(c *config.TLS) { c.ClientAuth = "verify_if_given" }), wantErr: "client_ca_file is required"},
+		{name: "empty client CA", cfg: with(func(c *config.TLS) { c.ClientAuth = "verify_if_given"; c.ClientCAFile = emptyCA }), wantErr: "no certificates found"},
+		{name: "missing key pair", cfg: with(func(c *config.TLS) { c.KeyFile = filepath.Join(dir, "missing.key") }), wantErr: "no such file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, reloader, err := New(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tlsConfig.MinVersion != tt.minVersion || tlsConfig.ClientAuth != tt.clientAuth {
+				t.Fatalf("min version %x, client auth %v", tlsConfig.MinVersion, tlsConfig.ClientAuth)
+			}
+			if !slices.Equal(tlsConfig.CipherSuites, tt.suites) {
+				t.Fatalf("cipher suites = %v, want %v", tlsConfig.CipherSuites, tt.suites)
+			}
+			if (tlsConfig.ClientCAs != nil) != (tt.clientAuth >= tls.VerifyClientCertIfGiven) {
+				t.Fatalf("client CAs = %v with client auth %v", tlsConfig.ClientCAs, tt.clientAuth)
+			}
+			if cert, _ := reloader.GetCertificate(nil); cert == nil {
+				t.Fatal("no certificate served")
+			}
+		})
+	}
+}
+
+func TestReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "first")
+	reloader, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 5*time.Millisecond)
+
+	// A half-written pair is ignored and the previous certificate kept.
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(keyFile, later, later)
+	time.Sleep(50 * time.Millisecond)
+	if got := servedName(t, reloader); got != "first" {
+		t.Fatalf("serving %q after a broken key was written, want first", got)
+	}
+
+	writeCert(t, dir, "second")
+	latest := later.Add(time.Minute)
+	os.Chtimes(certFile, latest, latest)
+	os.Chtimes(keyFile, latest, latest)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for servedName(t, reloader) != "second" {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate was not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReloaderWatchDisabled(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir(), "first")
+	reloader, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		t.Run(interval.String(), func(t *testing.T) {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				reloader.Watch(context.Background(), interval)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("Watch(%s) kept running, want it disabled", interval)
+			}
+		})
+	}
+}
+
+func servedName(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
@@ -0,0 +1,263 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedStorage starts a child span of the caller's context for every call
+// made to the wrapped backend.
+type tracedStorage struct {
+	next    storage.Storage
+	backend string
+	tracer  trace.Tracer
+}
+
+// InstrumentStorage wraps s so each method is recorded as a span tagged with
+// the given backend as db.system.
+func InstrumentStorage(s storage.Storage, backend string) storage.Storage {
+	return &tracedStorage{
+		next:    s,
+		backend: backend,
+		tracer:  otel.Tracer(instrumentationName),
+	}
+}
+
+// start opens a span for method; the returned func ends it, recording the
+// final error through a pointer, so callers write
+// `ctx, end := s.start(ctx, "Method"); defer end(&err)`.
+func (s *tracedStorage) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, func(*error)) {
+	attrs = append(attrs,
+		semconv.DBSystemKey.String(s.backend),
+		semconv.DBOperationName(method),
+	)
+	ctx, span := s.tracer.Start(ctx, "storage."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx, func(err *error) {
+		if *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+func (s *tracedStorage) CreateStudent(ctx context.Context, name string, email string, password string, age int) (id uint, err error) {
+	ctx, end := s.start(ctx, "CreateStudent")
+	defer end(&err)
+	return s.next.CreateStudent(ctx, name, email, password, age)
+}
+
+func (s *tracedStorage) CreateStudents(ctx context.Context, students []types.Student) (ids []uint, err error) {
+	ctx, end := s.start(ctx, "CreateStudents")
+	defer end(&err)
+	return s.next.CreateStudents(ctx, students)
+}
+
+func (s *tracedStorage) GetStudentById(ctx context.Context, id uint, includeDeleted bool) (student types.Student, err error) {
+	ctx, end := s.start(ctx, "GetStudentById", attribute.Int64("student.id", int64(id)))
+	defer end(&err)
+	return s.next.GetStudentById(ctx, id, includeDeleted)
+}
+
+func (s *tracedStorage) GetStudents(ctx context.Context, filter types.StudentFilter) (students []types.Student, err error) {
+	ctx, end := s.start(ctx, "GetStudents")
+	defer end(&err)
+	return s.next.GetStudents(ctx, filter)
+}
+
+func (s *tracedStorage) StreamStudents(ctx context.Context, filter types.StudentFilter, each func(types.Student) error) (err error) {
+	ctx, end := s.start(ctx, "StreamStudents")
+	defer end(&err)
+	return s.next.StreamStudents(ctx, filter, each)
+}
+
+func (s *tracedStorage) SearchStudents(ctx context.Context, search types.StudentSearch) (matches []types.StudentMatch, err error) {
+	ctx, end := s.start(ctx, "SearchStudents")
+	defer end(&err)
+	return s.next.SearchStudents(ctx, search)
+}
+
+func (s *tracedStorage) UpdateStudent(ctx context.Context, id uint, name string, email string, password string, age int) (err error) {
+	ctx, end := s.start(ctx, "UpdateStudent", attribute.Int64("student.id", int64(id)))
+	defer end(&err)
+	return s.next.UpdateStudent(ctx, id, name, email, password, age)
+}
+
+func (s *tracedStorage) DeleteStudent(ctx context.Context, id uint) (err error) {
+	ctx, end := s.start(ctx, "DeleteStudent", attribute.Int64("student.id", int64(id)))
+	defer end(&err)
+	return s.next.DeleteStudent(ctx, id)
+}
+
+func (s *tracedStorage) RestoreStudent(ctx context.Context, id uint) (err error) {
+	ctx, end := s.start(ctx, "RestoreStudent", attribute.Int64("student.id", int64(id)))
+	defer end(&err)
+	return s.next.RestoreStudent(ctx, id)
+}
+
+func (s *tracedStorage) PurgeDeletedStudents(ctx context.Context, before time.Time) (purged int64, err error) {
+	ctx, end := s.start(ctx, "PurgeDeletedStudents")
+	defer end(&err)
+	return s.next.PurgeDeletedStudents(ctx, before)
+}
+
+func (s *tracedStorage) GetStudentByEmail(ctx context.Context, email string) (student types.Student, err error) {
+	ctx, end := s.start(ctx, "GetStudentByEmail")
+	defer end(&err)
+	return s.next.GetStudentByEmail(ctx, email)
+}
+
+func (s *tracedStorage) CreateCourse(ctx context.Context, course types.Course) (id uint, err error) {
+	ctx, end := s.start(ctx, "CreateCourse")
+	defer end(&err)
+	return s.next.CreateCourse(ctx, course)
+}
+
+func (s *tracedStorage) GetCourse(ctx context.Context, id uint) (course types.Course, err error) {
+	ctx, end := s.start(ctx, "GetCourse", attribute.Int64("course.id", int64(id)))
+	defer end(&err)
+	return s.next.GetCourse(ctx, id)
+}
+
+func (s *tracedStorage) GetCourses(ctx context.Context) (courses []types.Course, err error) {
+	ctx, end := s.start(ctx, "GetCourses")
+	defer end(&err)
+	return s.next.GetCourses(ctx)
+}
+
+func (s *tracedStorage) UpdateCourse(ctx context.Context, course types.Course) (err error) {
+	ctx, end := s.start(ctx, "UpdateCourse", attribute.Int64("course.id", int64(course.ID)))
+	defer end(&err)
+	return s.next.UpdateCourse(ctx, course)
+}
+
+func (s *tracedStorage) DeleteCourse(ctx context.Context, id uint) (err error) {
+	ctx, end := s.start(ctx, "DeleteCourse", attribute.Int64("course.id", int64(id)))
+	defer end(&err)
+	return s.next.DeleteCourse(ctx, id)
+}
+
+func (s *tracedStorage) CreateEnrollment(ctx context.Context, studentID uint, courseID uint) (id uint, err error) {
+	ctx, end := s.start(ctx, "CreateEnrollment", attribute.Int64("student.id", int64(studentID)), attribute.Int64("course.id", int64(courseID)))
+	defer end(&err)
+	return s.next.CreateEnrollment(ctx, studentID, courseID)
+}
+
+func (s *tracedStorage) GetEnrollment(ctx context.Context, id uint) (enrollment types.Enrollment, err error) {
+	ctx, end := s.start(ctx, "GetEnrollment", attribute.Int64("enrollment.id", int64(id)))
+	defer end(&err)
+	return s.next.GetEnrollment(ctx, id)
+}
+
+func (s *tracedStorage) GetEnrollments(ctx context.Context, filter types.EnrollmentFilter) (enrollments []types.Enrollment, err error) {
+	ctx, end := s.start(ctx, "GetEnrollments")
+	defer end(&err)
+	return s.next.GetEnrollments(ctx, filter)
+}
+
+func (s *tracedStorage) UpdateEnrollmentStatus(ctx context.Context, id uint, status string) (err error) {
+	ctx, end := s.start(ctx, "UpdateEnrollmentStatus", attribute.Int64("enrollment.id", int64(id)))
+	defer end(&err)
+	return s.next.UpdateEnrollmentStatus(ctx, id, status)
+}
+
+func (s *tracedStorage) DeleteEnrollment(ctx context.Context, id uint) (err error) {
+	ctx, end := s.start(ctx, "DeleteEnrollment", attribute.Int64("enrollment.id", int64(id)))
+	defer end(&err)
+	return s.next.DeleteEnrollment(ctx, id)
+}
+
+func (s *tracedStorage) GetStudentCourses(ctx context.Context, studentID uint) (courses []types.StudentCourse, err error) {
+	ctx, end := s.start(ctx, "GetStudentCourses", attribute.Int64("student.id", int64(studentID)))
+	defer end(&err)
+	return s.next.GetStudentCourses(ctx, studentID)
+}
+
+func (s *tracedStorage) SetGrade(ctx context.Context, grade types.Grade) (saved types.Grade, err error) {
+	ctx, end := s.start(ctx, "SetGrade", attribute.Int64("enrollment.id", int64(grade.EnrollmentID)))
+	defer end(&err)
+	return s.next.SetGrade(ctx, grade)
+}
+
+func (s *tracedStorage) GetGrade(ctx context.Context, enrollmentID uint) (grade types.Grade, err error) {
+	ctx, end := s.start(ctx, "GetGrade", attribute.Int64("enrollment.id", int64(enrollmentID)))
+	defer end(&err)
+	return s.next.GetGrade(ctx, enrollmentID)
+}
+
+func (s *tracedStorage) DeleteGrade(ctx context.Context, enrollmentID uint) (err error) {
+	ctx, end := s.start(ctx, "DeleteGrade", attribute.Int64("enrollment.id", int64(enrollmentID)))
+	defer end(&err)
+	return s.next.DeleteGrade(ctx, enrollmentID)
+}
+
+func (s *tracedStorage) CreateAuditEntry(ctx context.Context, entry types.AuditEntry) (err error) {
+	ctx, end := s.start(ctx, "CreateAuditEntry")
+	defer end(&err)
+	return s.next.CreateAuditEntry(ctx, entry)
+}
+
+func (s *tracedStorage) GetAuditEntries(ctx context.Context, filter types.AuditFilter) (entries []types.AuditEntry, err error) {
+	ctx, end := s.start(ctx, "GetAuditEntries")
+	defer end(&err)
+	return s.next.GetAuditEntries(ctx, filter)
+}
+
+func (s *tracedStorage) CreateIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) (err error) {
+	ctx, end := s.start(ctx, "CreateIdempotencyRecord")
+	defer end(&err)
+	return s.next.CreateIdempotencyRecord(ctx, record)
+}
+
+func (s *tracedStorage) GetIdempotencyRecord(ctx context.Context, owner string, key string) (record types.IdempotencyRecord, err error) {
+	ctx, end := s.start(ctx, "GetIdempotencyRecord")
+	defer end(&err)
+	return s.next.GetIdempotencyRecord(ctx, owner, key)
+}
+
+func (s *tracedStorage) CompleteIdempotencyRecord(ctx context.Context, record types.IdempotencyRecord) (err error) {
+	ctx, end := s.start(ctx, "CompleteIdempotencyRecord")
+	defer end(&err)
+	return s.next.CompleteIdempotencyRecord(ctx, record)
+}
+
+func (s *tracedStorage) DeleteIdempotencyRecord(ctx context.Context, owner string, key string) (err error) {
+	ctx, end := s.start(ctx, "DeleteIdempotencyRecord")
+	defer end(&err)
+	return s.next.DeleteIdempotencyRecord(ctx, owner, key)
+}
+
+func (s *tracedStorage) PurgeExpiredIdempotencyRecords(ctx context.Context, before time.Time) (purged int64, err error) {
+	ctx, end := s.start(ctx, "PurgeExpiredIdempotencyRecords")
+	defer end(&err)
+	return s.next.PurgeExpiredIdempotencyRecords(ctx, before)
+}
+
+func (s *tracedStorage) DBStats() sql.DBStats {
+	return s.next.DBStats()
+}
+
+func (s *tracedStorage) Ping(ctx context.Context) (err error) {
+	ctx, end := s.start(ctx, "Ping")
+	defer end(&err)
+	return s.next.Ping(ctx)
+}
+
+func (s *tracedStorage) CheckMigrations(ctx context.Context) (err error) {
+	ctx, end := s.start(ctx, "CheckMigrations")
+	defer end(&err)
+	return s.next.CheckMigrations(ctx)
+}
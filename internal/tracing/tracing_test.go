@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/config"
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// courseStore answers GetCourse; every other storage method is left nil.
+type courseStore struct {
+	storage.Storage
+	err error
+}
+
+func (s courseStore) GetCourse(ctx context.Context, id uint) (types.Course, error) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return types.Course{}, errors.New("backend called without the storage span")
+	}
+	return types.Course{ID: id}, s.err
+}
+
+func TestInstrumentStorage(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status codes.Code
+	}{
+		{name: "ok", status: codes.Unset},
+		{name: "error", err: storage.ErrNotFound, status: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			otel.SetTracerProvider(provider)
+			s := InstrumentStorage(courseStore{err: tt.err}, "sqlite")
+
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+			_, err := s.GetCourse(ctx, 3)
+			parent.End()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("GetCourse() = %v, want %v", err, tt.err)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("recorded %d spans, want 2", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != "storage.GetCourse" || span.SpanKind() != trace.SpanKindClient {
+				t.Fatalf("span = %s (%s), want storage.GetCourse (client)", span.Name(), span.SpanKind())
+			}
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Fatal("storage span is not a child of the caller's span")
+			}
+			if span.Status().Code != tt.status {
+				t.Fatalf("status = %v, want %v", span.Status().Code, tt.status)
+			}
+
+			attrs := map[string]string{}
+			for _, attr := range span.Attributes() {
+				attrs[string(attr.Key)] = attr.Value.Emit()
+			}
+			if attrs["db.system"] != "sqlite" || attrs["db.operation.name"] != "GetCourse" {
+				t.Fatalf("attributes = %v", attrs)
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		cfg     config.Tracing
+		wantErr string
+	}{
+		{name: "disabled", cfg: config.Tracing{}},
+		{name: "none", cfg: config.Tracing{Exporter: "none"}},
+		{name: "stdout", cfg: config.Tracing{Exporter: "stdout", ServiceName: "crud-api", SampleRatio: 1}},
+		{name: "file", cfg: config.Tracing{Exporter: "file", FilePath: filepath.Join(dir, "spans.json"), ServiceName: "crud-api", SampleRatio: 1}},
+		{name: "unwritable file", cfg: config.Tracing{Exporter: "file", FilePath: filepath.Join(dir, "missing", "spans.json")}, wantErr: "no such file"},
+		{name: "unknown", cfg: config.Tracing{Exporter: "zipkin"}, wantErr: `unknown tracing exporter "zipkin"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Setup() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.cfg.Exporter == "file" {
+				_, span := otel.Tracer("test").Start(context.Background(), "written")
+				span.End()
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.cfg.Exporter == "file" {
+				written, err := os.ReadFile(tt.cfg.FilePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(written), `"Name":"written"`) || !strings.Contains(string(written), "crud-api") {
+					t.Fatalf("span file = %s", written)
+				}
+			}
+		})
+	}
+}
@@ -1,16 +1,166 @@
 package types
 
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RoleStudent = "student"
+	RoleAdmin   = "admin"
+)
+
 type Student struct {
-	ID       uint `gorm:"primaryKey"`
-	Name     string
-	Email    string `gorm:"unique"`
-	Password string
-	Age      int
+	ID        uint           `gorm:"primaryKey"`
+	Name      string         `validate:"required,min=2,max=100"`
+	Email     string         `validate:"required,email,max=254"`
+	Password  string         `validate:"omitempty,max=72,password"`
+	Age       int            `validate:"gte=1,lte=120"`
+	Role      string         `gorm:"not null;default:student"`
+	CreatedAt time.Time      `gorm:"index"`
+	UpdatedAt time.Time      `gorm:"index"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
+// StudentResponse is how a student is shown to API clients, leaving out the
+// password hash. DeletedAt is only set on soft-deleted students.
 type StudentResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Age   int    `json:"age"`
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Age       int        `json:"age"`
+	Role      string     `json:"role,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// StudentFilter narrows the result of a student listing. Zero values match
+// everything; a zero Limit returns all rows after Offset.
+type StudentFilter struct {
+	IncludeDeleted bool
+	CreatedAfter   time.Time
+	UpdatedSince   time.Time
+	Limit          int
+	Offset         int
+}
+
+// StudentSearch is a full-text query over student names and emails, narrowed
+// and paginated like a listing.
+type StudentSearch struct {
+	StudentFilter
+	Query string
+}
+
+// StudentMatch is a search hit. Scores are only comparable within the
+// results of one query.
+type StudentMatch struct {
+	Student Student
+	Score   float64
+}
+
+// Course is a class students can enroll in. Enrolled counts the enrollments
+// holding a seat; it is computed when courses are read and never stored.
+type Course struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Code      string    `gorm:"uniqueIndex;not null" json:"code" validate:"required,max=20"`
+	Title     string    `gorm:"not null" json:"title" validate:"required,max=200"`
+	Credits   float64   `gorm:"not null;default:0" json:"credits" validate:"gte=0,lte=30"`
+	Capacity  int       `gorm:"not null" json:"capacity" validate:"gte=1,lte=10000"`
+	Enrolled  int       `gorm:"->;-:migration" json:"enrolled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Only enrollments with EnrollmentStatusEnrolled take up a seat in a course.
+const (
+	EnrollmentStatusEnrolled  = "enrolled"
+	EnrollmentStatusDropped   = "dropped"
+	EnrollmentStatusCompleted = "completed"
+)
+
+// Enrollment places a student in a course. A student has at most one
+// enrollment per course; dropping and re-enrolling reuses it.
+type Enrollment struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	StudentID uint      `gorm:"not null;uniqueIndex:idx_enrollments_student_course" json:"student_id"`
+	CourseID  uint      `gorm:"not null;uniqueIndex:idx_enrollments_student_course;index" json:"course_id"`
+	Status    string    `gorm:"not null;default:enrolled" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EnrollmentFilter narrows the result of an enrollment listing. Zero values match everything.
+type EnrollmentFilter struct {
+	StudentID uint
+	CourseID  uint
+	Status    string
+}
+
+// Grade is the result a student earned in one enrollment, in Term. Letter and
+// Points are resolved from Value on Scale when the grade is recorded, so later
+// changes to a scale leave recorded grades alone.
+type Grade struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	EnrollmentID uint      `gorm:"uniqueIndex;not null" json:"enrollment_id"`
+	Term         string    `gorm:"not null" json:"term"`
+	Scale        string    `gorm:"not null" json:"scale"`
+	Value        string    `gorm:"not null" json:"value"`
+	Letter       string    `gorm:"not null" json:"letter"`
+	Points       float64   `gorm:"not null" json:"points"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// StudentCourse is a course seen through one student's enrollment in it.
+// Grade is nil until one is recorded.
+type StudentCourse struct {
+	EnrollmentID uint      `json:"enrollment_id"`
+	Status       string    `json:"status"`
+	EnrolledAt   time.Time `json:"enrolled_at"`
+	Course       Course    `json:"course"`
+	Grade        *Grade    `json:"grade,omitempty"`
+}
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// AuditEntry records a single mutation of a student. Changes holds a JSON
+// object mapping each changed field to its before and after values.
+type AuditEntry struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	Actor     string          `gorm:"index" json:"actor"`
+	Action    string          `json:"action"`
+	TargetID  uint            `gorm:"index" json:"target_id"`
+	Changes   json.RawMessage `json:"changes"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `gorm:"index" json:"created_at"`
+}
+
+// AuditFilter narrows the result of an audit listing. Zero values match everything.
+type AuditFilter struct {
+	TargetID uint
+	Actor    string
+	Since    time.Time
+}
+
+// IdempotencyRecord remembers the outcome of a request sent with an
+// Idempotency-Key so retries can be answered without repeating it. Keys are
+// scoped to the authenticated Owner. StatusCode is zero while the original
+// request is still being processed.
+type IdempotencyRecord struct {
+	Owner       string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey;column:idempotency_key"`
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"index"`
 }
@@ -0,0 +1,259 @@
+// Package codec encodes and decodes API payloads as JSON, XML, MessagePack or
+// CBOR. Every format uses the field names of the JSON encoding, so a value
+// reads the same whichever representation a client negotiates.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec is one wire format.
+type Codec interface {
+	// MediaType is the value sent in Content-Type.
+	MediaType() string
+	// Encode writes v to w.
+	Encode(w io.Writer, v any) error
+	// Decode reads exactly one value from r into v, rejecting fields v does
+	// not have. An empty body is reported as io.EOF.
+	Decode(r io.Reader, v any) error
+}
+
+var (
+	JSON        Codec = jsonCodec{}
+	XML         Codec = xmlCodec{}
+	MessagePack Codec = msgpackCodec{}
+	CBOR        Codec = cborCodec{}
+)
+
+// format maps the media types a codec answers to. suffix is its structured
+// syntax suffix (RFC 6839), so that e.g. application/problem+json selects JSON.
+type format struct {
+	codec      Codec
+	mediaTypes []string
+	suffix     string
+}
+
+// formats is ordered by server preference, which breaks ties between equally
+// acceptable media types.
+var formats = []format{
+	{JSON, []string{"application/json"}, "+json"},
+	{XML, []string{"application/xml", "text/xml"}, "+xml"},
+	{MessagePack, []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, "+msgpack"},
+	{CBOR, []string{"application/cbor"}, "+cbor"},
+}
+
+// Supported lists the canonical media type of every codec.
+func Supported() []string {
+	types := make([]string, len(formats))
+	for i, f := range formats {
+		types[i] = f.codec.MediaType()
+	}
+	return types
+}
+
+// ForContentType returns the codec that decodes a Content-Type header value.
+func ForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	for _, f := range formats {
+		for _, t := range f.mediaTypes {
+			if mediaType == t {
+				return f.codec, true
+			}
+		}
+		if strings.HasSuffix(mediaType, f.suffix) {
+			return f.codec, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate picks the codec best matching an Accept header, honouring
+// q-values and wildcards. An empty header accepts JSON; false means nothing
+// the client accepts can be produced.
+func Negotiate(accept string) (Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, true
+	}
+
+	var (
+		best  Codec
+		bestQ float64
+	)
+	for _, f := range formats {
+		if q := f.quality(accept); q > bestQ {
+			best, bestQ = f.codec, q
+		}
+	}
+	return best, best != nil
+}
+
+// quality is the q-value the Accept header gives this format, taken from its
+// most specific matching range.
+func (f format) quality(accept string) float64 {
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		s := f.match(mediaRange)
+		if s <= specificity {
+			continue
+		}
+
+		rangeQ := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				rangeQ = parsed
+			}
+		}
+		q, specificity = rangeQ, s
+	}
+	return q
+}
+
+// match reports how specifically mediaRange names this format: 3 for an exact
+// media type, 2 for a structured syntax suffix, 1 for type/* and 0 for */*.
+// It returns -1 when the range does not match at all.
+func (f format) match(mediaRange string) int {
+	specificity := -1
+	for _, t := range f.mediaTypes {
+		switch {
+		case mediaRange == t:
+			return 3
+		case mediaRange == "*/*":
+			specificity = max(specificity, 0)
+		case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(t, strings.TrimSuffix(mediaRange, "*")):
+			specificity = max(specificity, 1)
+		}
+	}
+	if strings.HasSuffix(mediaRange, f.suffix) {
+		specificity = max(specificity, 2)
+	}
+	return specificity
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaType() string { return "application/json" }
+
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return err
+		}
+		return errors.New("request body must contain a single JSON value")
+	}
+	return nil
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaType() string { return "application/msgpack" }
+
+func (msgpackCodec) Encode(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	return encoder.Encode(plain(tree))
+}
+
+func (msgpackCodec) Decode(r io.Reader, v any) error {
+	data, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	reader := bytes.NewReader(data)
+	var tree any
+	if err := msgpack.NewDecoder(reader).Decode(&tree); err != nil {
+		return fmt.Errorf("invalid MessagePack: %w", err)
+	}
+	if reader.Len() > 0 {
+		return errors.New("request body must contain a single MessagePack value")
+	}
+	return fromTree(tree, v)
+}
+
+var (
+	cborEncMode, _ = cbor.EncOptions{Sort: cbor.SortBytewiseLexical}.EncMode()
+	cborDecMode, _ = cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]any(nil))}.DecMode()
+)
+
+type cborCodec struct{}
+
+func (cborCodec) MediaType() string { return "application/cbor" }
+
+func (cborCodec) Encode(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	return cborEncMode.NewEncoder(w).Encode(plain(tree))
+}
+
+func (cborCodec) Decode(r io.Reader, v any) error {
+	data, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	var tree any
+	if err := cborDecMode.Unmarshal(data, &tree); err != nil {
+		return fmt.Errorf("invalid CBOR: %w", err)
+	}
+	return fromTree(tree, v)
+}
+
+// readBody reads all of r, reporting an empty body as io.EOF like the JSON
+// decoder does.
+func readBody(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, io.EOF
+	}
+	return data, nil
+}
+
+// fromTree stores a generically decoded value in v through the strict JSON
+// decoder, so binary formats accept exactly what JSON accepts.
+func fromTree(tree any, v any) error {
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("unsupported value: %w", err)
+	}
+	return JSON.Decode(bytes.NewReader(data), v)
+}
@@ -0,0 +1,234 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Codec
+	}{
+		{accept: "", want: JSON},
+		{accept: "*/*", want: JSON},
+		{accept: "application/json", want: JSON},
+		{accept: "application/xml", want: XML},
+		{accept: "text/xml", want: XML},
+		{accept: "application/x-msgpack", want: MessagePack},
+		{accept: "application/vnd.msgpack", want: MessagePack},
+		{accept: "application/cbor", want: CBOR},
+		{accept: "application/problem+xml", want: XML},
+		{accept: "text/*", want: XML},
+		{accept: "application/*", want: JSON},
+		{accept: "application/json;q=0.5, application/xml", want: XML},
+		{accept: "application/xml;q=0.9, application/msgpack;q=0.95", want: MessagePack},
+		{accept: "*/*;q=0.1, application/cbor", want: CBOR},
+		{accept: "application/xml, application/json", want: JSON},
+		{accept: "application/json;q=0, */*", want: XML},
+		{accept: "text/html, not a media type, application/cbor;q=0.2", want: CBOR},
+		{accept: "text/html", want: nil},
+		{accept: "application/json;q=0", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			got, ok := Negotiate(tt.accept)
+			if ok != (tt.want != nil) || got != tt.want {
+				t.Fatalf("Negotiate(%q) = %v, %v, want %v", tt.accept, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        Codec
+	}{
+		{contentType: "application/json", want: JSON},
+		{contentType: "application/json; charset=utf-8", want: JSON},
+		{contentType: "application/merge-patch+json", want: JSON},
+		{contentType: "text/xml; charset=utf-8", want: XML},
+		{contentType: "application/msgpack", want: MessagePack},
+		{contentType: "application/cbor", want: CBOR},
+		{contentType: "text/plain", want: nil},
+		{contentType: "", want: nil},
+		{contentType: "application/", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			got, ok := ForContentType(tt.contentType)
+			if ok != (tt.want != nil) || got != tt.want {
+				t.Fatalf("ForContentType(%q) = %v, %v, want %v", tt.contentType, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+type address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type record struct {
+	ID        uint              `json:"id"`
+	Name      string            `json:"name"`
+	Score     float64           `json:"score"`
+	Active    bool              `json:"active"`
+	Tags      []string          `json:"tags"`
+	Address   address           `json:"address"`
+	Previous  *address          `json:"previous"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"created_at"`
+	Ignored   string            `json:"-"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2026, 3, 4, 5, 6, 7, 890000000, time.UTC)
+	values := []struct {
+		name  string
+		value record
+	}{
+		{
+			name: "full",
+			value: record{
+				ID: 7, Name: "Ada <Lovelace> & co", Score: 3.75, Active: true,
+				Tags:      []string{"a", "b"},
+				Address:   address{City: "London", Zip: "N1"},
+				Previous:  &address{City: "Paris"},
+				Labels:    map[string]string{"team": "red"},
+				CreatedAt: created,
+			},
+		},
+		{
+			name:  "zero values",
+			value: record{Tags: []string{}, Labels: map[string]string{}, CreatedAt: created},
+		},
+	}
+
+	for _, c := range []Codec{JSON, XML, MessagePack, CBOR} {
+		for _, v := range values {
+			t.Run(c.MediaType()+" "+v.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := c.Encode(&buf, v.value); err != nil {
+					t.Fatal(err)
+				}
+
+				var got record
+				if err := c.Decode(&buf, &got); err != nil {
+					t.Fatalf("Decode: %v\n%s", err, buf.String())
+				}
+				if !got.CreatedAt.Equal(v.value.CreatedAt) {
+					t.Fatalf("created_at = %v, want %v", got.CreatedAt, v.value.CreatedAt)
+				}
+				got.CreatedAt = v.value.CreatedAt
+				if !reflect.DeepEqual(normalise(got), normalise(v.value)) {
+					t.Fatalf("round trip = %+v, want %+v", got, v.value)
+				}
+			})
+		}
+	}
+}
+
+// normalise treats empty and nil collections alike, which not every format
+// can tell apart.
+func normalise(r record) record {
+	if len(r.Tags) == 0 {
+		r.Tags = nil
+	}
+	if len(r.Labels) == 0 {
+		r.Labels = nil
+	}
+	return r
+}
+
+func TestDecodeRejectsUnknownFields(t *testing.T) {
+	var source bytes.Buffer
+	tests := []struct {
+		codec Codec
+		body  func() []byte
+	}{
+		{JSON, func() []byte { return []byte(`{"city":"x","extra":1}`) }},
+		{XML, func() []byte { return []byte(`<request><city>x</city><extra>1</extra></request>`) }},
+		{MessagePack, func() []byte {
+			source.Reset()
+			MessagePack.Encode(&source, map[string]any{"city": "x", "extra": 1})
+			return source.Bytes()
+		}},
+		{CBOR, func() []byte {
+			source.Reset()
+			CBOR.Encode(&source, map[string]any{"city": "x", "extra": 1})
+			return source.Bytes()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.codec.MediaType(), func(t *testing.T) {
+			var dst address
+			err := tt.codec.Decode(bytes.NewReader(tt.body()), &dst)
+			if err == nil || !strings.Contains(err.Error(), "extra") {
+				t.Fatalf("Decode() = %v, want an error naming the unknown field", err)
+			}
+		})
+	}
+}
+
+func TestDecodeEmptyBody(t *testing.T) {
+	for _, c := range []Codec{JSON, XML, MessagePack, CBOR} {
+		t.Run(c.MediaType(), func(t *testing.T) {
+			var dst address
+			if err := c.Decode(strings.NewReader(""), &dst); !errors.Is(err, io.EOF) {
+				t.Fatalf("Decode(empty) = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestXMLEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			name:  "object",
+			value: address{City: "Oslo"},
+			want:  "<response><city>Oslo</city></response>",
+		},
+		{
+			name:  "array items and null",
+			value: map[string]any{"ids": []int{1, 2}, "next": nil},
+			want:  "<response><ids><item>1</item><item>2</item></ids><next></next></response>",
+		},
+		{
+			name:  "keys that are not XML names",
+			value: map[string]int{"1st": 1},
+			want:  `<response><entry key="1st">1</entry></response>`,
+		},
+		{
+			name:  "escaped text",
+			value: map[string]string{"name": "a < b & c"},
+			want:  "<response><name>a &lt; b &amp; c</name></response>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := XML.Encode(&buf, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(strings.TrimPrefix(buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`))
+			if got != tt.want {
+				t.Fatalf("XML = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// object is a JSON object whose members keep their encoded order, so that
+// XML elements follow struct field order.
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+// toTree turns v into objects, []any, strings, json.Numbers, bools and nils by
+// way of its JSON encoding, which applies json tags and Marshaler methods.
+func toTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return readTree(decoder)
+}
+
+func readTree(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err := decoder.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for decoder.More() {
+			value, err := readTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := decoder.Token()
+		return arr, err
+	case json.Delim('}'), json.Delim(']'):
+		return nil, fmt.Errorf("unexpected %v", token)
+	default:
+		return token, nil
+	}
+}
+
+// plain converts a tree for the binary encoders: objects become maps and
+// numbers become int64, uint64 or float64.
+func plain(tree any) any {
+	switch v := tree.(type) {
+	case object:
+		m := make(map[string]any, len(v))
+		for _, member := range v {
+			m[member.key] = plain(member.value)
+		}
+		return m
+	case []any:
+		for i := range v {
+			v[i] = plain(v[i])
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
@@ -0,0 +1,303 @@
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// XMLRoot is implemented by values that need a root element other than
+// <response>, such as RFC 7807 problem documents.
+type XMLRoot interface {
+	XMLRoot() xml.Name
+}
+
+// Objects become elements named after their keys, array items become <item>
+// elements and null becomes an empty element. Keys that are not valid XML
+// names are written as <entry key="...">.
+type xmlCodec struct{}
+
+func (xmlCodec) MediaType() string { return "application/xml" }
+
+func (xmlCodec) Encode(w io.Writer, v any) error {
+	root := xml.Name{Local: "response"}
+	if r, ok := v.(XMLRoot); ok {
+		root = r.XMLRoot()
+	}
+
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if err := writeElement(encoder, xml.StartElement{Name: root}, tree); err != nil {
+		return err
+	}
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func writeElement(encoder *xml.Encoder, start xml.StartElement, value any) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case object:
+		for _, member := range v {
+			if err := writeElement(encoder, memberElement(member.key), member.value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := writeElement(encoder, xml.StartElement{Name: xml.Name{Local: "item"}}, item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+func memberElement(key string) xml.StartElement {
+	if isXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+	}
+}
+
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Decode reads the document into a tree and converts it to JSON guided by
+// v's type, since XML text carries no distinction between strings, numbers
+// and booleans.
+func (xmlCodec) Decode(r io.Reader, v any) error {
+	root, err := parseXML(r)
+	if err != nil {
+		return err
+	}
+
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return errors.New("codec: Decode needs a pointer")
+	}
+
+	data, err := json.Marshal(root.value(t.Elem()))
+	if err != nil {
+		return fmt.Errorf("unsupported value: %w", err)
+	}
+	return JSON.Decode(bytes.NewReader(data), v)
+}
+
+type xmlNode struct {
+	name     string
+	text     strings.Builder
+	children []*xmlNode
+}
+
+func parseXML(r io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(r)
+
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if root != nil && len(stack) == 0 {
+				return nil, errors.New("request body must contain a single XML document")
+			}
+			node := &xmlNode{name: t.Name.Local}
+			for _, attr := range t.Attr {
+				if t.Name.Local == "entry" && attr.Name.Local == "key" {
+					node.name = attr.Value
+				}
+			}
+			if len(stack) == 0 {
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, errors.New("request body must contain a single XML document")
+			}
+		case xml.Directive:
+			return nil, errors.New("XML directives are not allowed")
+		}
+	}
+
+	if root == nil {
+		return nil, io.EOF
+	}
+	return root, nil
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// value converts n to the JSON value that t would be decoded from.
+func (n *xmlNode) value(t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	text := n.text.String()
+	trimmed := strings.TrimSpace(text)
+	if len(n.children) == 0 && trimmed == "" && t.Kind() != reflect.String {
+		return nil
+	}
+
+	if t == timeType || reflect.PointerTo(t).Implements(jsonUnmarshaler) || reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return trimmed
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return text
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			return b
+		}
+		return trimmed
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return json.Number(trimmed)
+		}
+		return trimmed
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return trimmed
+		}
+		items := make([]any, len(n.children))
+		for i, child := range n.children {
+			items[i] = child.value(t.Elem())
+		}
+		return items
+	case reflect.Map:
+		m := make(map[string]any, len(n.children))
+		for _, child := range n.children {
+			m[child.name] = child.value(t.Elem())
+		}
+		return m
+	case reflect.Struct:
+		m := make(map[string]any, len(n.children))
+		for _, child := range n.children {
+			if field, ok := fieldType(t, child.name); ok {
+				m[child.name] = child.value(field)
+			} else {
+				// Left for the JSON decoder to reject as an unknown field.
+				m[child.name] = child.generic()
+			}
+		}
+		return m
+	default:
+		return n.generic()
+	}
+}
+
+// generic converts n without type information: elements with children
+// become objects and all others strings.
+func (n *xmlNode) generic() any {
+	if len(n.children) == 0 {
+		return n.text.String()
+	}
+	m := make(map[string]any, len(n.children))
+	for _, child := range n.children {
+		m[child.name] = child.generic()
+	}
+	return m
+}
+
+// fieldType finds the field encoding/json would decode name into, including
+// fields promoted from embedded structs.
+func fieldType(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && jsonName == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found, ok := fieldType(embedded, name); ok {
+					return found, true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if strings.EqualFold(jsonName, name) {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+type contextKey struct{}
+
+// New returns a text logger for local development and a JSON logger for
+// every other environment.
+func New(env string) *slog.Logger {
+	switch env {
+	case "local", "dev":
+		return slog.New(slog.NewTextHandler(os.Stdout, nil))
+	default:
+		return slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	}
+}
+
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger, or the default logger when
+// ctx does not carry one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
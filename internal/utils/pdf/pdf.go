@@ -0,0 +1,193 @@
+// Package pdf writes simple text documents as PDF. Only the standard Helvetica
+// fonts are used, which every viewer provides, so nothing is embedded and
+// text is limited to the Windows-1252 character set.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const ContentType = "application/pdf"
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Document is a PDF built in memory one page at a time. Coordinates are in
+// points from the top left corner of the page, y growing downwards.
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+}
+
+// New starts an empty document whose metadata title is title.
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// AddPage starts a new page; everything drawn afterwards goes on it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws text with its baseline starting at x, y. Characters outside
+// Windows-1252 are drawn as '?'.
+func (d *Document) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(d.page(), "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		font+1, number(size), number(x), number(PageHeight-y), escape(encode(text)))
+}
+
+// TextRight draws text so that it ends at x.
+func (d *Document) TextRight(x, y float64, font Font, size float64, text string) {
+	d.Text(x-TextWidth(text, font, size), y, font, size, text)
+}
+
+// Line draws a straight line width points thick.
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%s w %s %s m %s %s l S\n",
+		number(width), number(x1), number(PageHeight-y1), number(x2), number(PageHeight-y2))
+}
+
+// TextWidth is how wide text is drawn in font at size.
+func TextWidth(text string, font Font, size float64) float64 {
+	widths := &helveticaWidths
+	if font == Bold {
+		widths = &helveticaBoldWidths
+	}
+
+	var total int
+	for _, c := range encode(text) {
+		if c >= 32 {
+			total += int(widths[c-32])
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Truncate shortens text with an ellipsis until it fits in width.
+func Truncate(text string, font Font, size, width float64) string {
+	if TextWidth(text, font, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := string(runes) + "…"; TextWidth(candidate, font, size) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// WriteTo writes the finished document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are fixed; each page then takes a page object followed
+	// by its content stream.
+	kids := make([]byte, 0, len(d.pages)*8)
+	for i := range d.pages {
+		kids = fmt.Appendf(kids, "%d 0 R ", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		bytes.TrimSpace(kids), len(d.pages), number(PageWidth), number(PageHeight)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", 6+2*i))
+
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (crud-api) >>", escape(encode(d.title))))
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, len(offsets), xref)
+
+	return buf.WriteTo(w)
+}
+
+// encode converts text to Windows-1252, the encoding the fonts are declared
+// with.
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// escape makes encoded text safe inside a PDF literal string.
+func escape(text []byte) []byte {
+	out := make([]byte, 0, len(text))
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			out = append(out, '\\', c)
+		case c < 32:
+			out = fmt.Appendf(out, "\\%03o", c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// number formats a coordinate or size to a hundredth of a point.
+func number(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,176 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "plain", want: "plain"},
+		{text: "f(x) = \\y", want: `f\(x\) = \\y`},
+		{text: "tab\there", want: `tab\011here`},
+		{text: "café", want: "caf\xe9"},
+		{text: "snow ☃", want: "snow ?"},
+		{text: "€5", want: "\x805"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := string(escape(encode(tt.text))); got != tt.want {
+				t.Fatalf("escape(encode(%q)) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{v: 0, want: "0"},
+		{v: 12, want: "12"},
+		{v: 12.5, want: "12.5"},
+		{v: 841.89, want: "841.89"},
+		{v: 1.005, want: "1"},
+		{v: -3.25, want: "-3.25"},
+	}
+
+	for _, tt := range tests {
+		if got := number(tt.v); got != tt.want {
+			t.Errorf("number(%g) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width float64
+		want  string
+	}{
+		{name: "fits", text: "Short", width: 100, want: "Short"},
+		{name: "too long", text: "Introduction to Computer Science", width: 60, want: "Introductio…"},
+		{name: "nothing fits", text: "Anything", width: 1, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.text, Regular, 10, tt.width)
+			if got != tt.want {
+				t.Fatalf("Truncate() = %q, want %q", got, tt.want)
+			}
+			if TextWidth(got, Regular, 10) > tt.width {
+				t.Fatalf("Truncate() = %q is wider than %g", got, tt.width)
+			}
+		})
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	// Helvetica's space is 278 units wide and bold "W" 944.
+	if got := TextWidth("  ", Regular, 10); got != 5.56 {
+		t.Errorf("TextWidth of two spaces = %g, want 5.56", got)
+	}
+	if got := TextWidth("W", Bold, 1000); got != 944 {
+		t.Errorf("TextWidth of bold W = %g, want 944", got)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages int
+	}{
+		{name: "empty document", pages: 0},
+		{name: "one page", pages: 1},
+		{name: "three pages", pages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New("Transcript (draft)")
+			for i := range tt.pages {
+				doc.AddPage()
+				doc.Text(50, 60, Bold, 12, "Page "+strconv.Itoa(i+1))
+				doc.Line(50, 70, 545, 70, 1)
+			}
+
+			var buf bytes.Buffer
+			if _, err := doc.WriteTo(&buf); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.Bytes()
+
+			if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+				t.Fatal("missing PDF header or trailer")
+			}
+			if !bytes.Contains(out, []byte(`/Title (Transcript \(draft\))`)) {
+				t.Error("title is missing or unescaped")
+			}
+
+			wantPages := max(tt.pages, 1)
+			if !bytes.Contains(out, []byte("/Count "+strconv.Itoa(wantPages)+" ")) {
+				t.Errorf("page count is not %d", wantPages)
+			}
+			checkXref(t, out)
+
+			if tt.pages > 0 {
+				content := firstStream(t, out)
+				if !bytes.Contains(content, []byte("BT /F2 12 Tf 50 781.89 Td (Page 1) Tj ET")) {
+					t.Errorf("first page content = %q", content)
+				}
+			}
+		})
+	}
+}
+
+// checkXref verifies that every cross-reference entry points at the object
+// it numbers.
+func checkXref(t *testing.T, out []byte) {
+	t.Helper()
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if start == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(start[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		want := strconv.Itoa(i+1) + " 0 obj\n"
+		if !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, out[offset:offset+10], want)
+		}
+	}
+}
+
+func firstStream(t *testing.T, out []byte) []byte {
+	t.Helper()
+	match := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindSubmatchIndex(out)
+	if match == nil {
+		t.Fatal("no content stream")
+	}
+	length, _ := strconv.Atoi(string(out[match[2]:match[3]]))
+	zr, err := zlib.NewReader(bytes.NewReader(out[match[1] : match[1]+length]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
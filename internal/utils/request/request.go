@@ -0,0 +1,47 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Saidurbu/go-lang-crud/internal/utils/codec"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+// Decode strictly decodes a single value from r's body into dst in the
+// format named by its Content-Type, which defaults to JSON: unknown fields and
+// trailing data are rejected, and bodies cut off by http.MaxBytesReader are
+// reported as 413.
+func Decode(r *http.Request, dst interface{}) *response.Problem {
+	c := codec.JSON
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		var ok bool
+		if c, ok = codec.ForContentType(contentType); !ok {
+			return response.NewProblem(http.StatusUnsupportedMediaType, response.CodeUnsupportedMediaType,
+				fmt.Sprintf("content type %q is not supported, use one of %s", contentType, strings.Join(codec.Supported(), ", ")))
+		}
+	}
+
+	if err := c.Decode(r.Body, dst); err != nil {
+		return BodyProblem(err)
+	}
+	return nil
+}
+
+// BodyProblem maps an error from reading or decoding a request body to the
+// problem reported to the client.
+func BodyProblem(err error) *response.Problem {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, io.EOF):
+		return response.NewProblem(http.StatusBadRequest, response.CodeEmptyBody, "empty request body")
+	case errors.As(err, &maxBytesErr):
+		return response.NewProblem(http.StatusRequestEntityTooLarge, response.CodePayloadTooLarge,
+			fmt.Sprintf("request body must not exceed %d bytes", maxBytesErr.Limit))
+	default:
+		return response.NewProblem(http.StatusBadRequest, response.CodeInvalidBody, err.Error())
+	}
+}
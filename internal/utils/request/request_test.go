@@ -0,0 +1,64 @@
+package request
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/utils/codec"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/response"
+)
+
+type student struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func TestDecode(t *testing.T) {
+	var cbor bytes.Buffer
+	codec.CBOR.Encode(&cbor, student{Name: "Ada", Email: "ada@example.com"})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        string
+	}{
+		{name: "json", body: `{"name":"Ada","email":"ada@example.com"}`},
+		{name: "json with charset", contentType: "application/json; charset=utf-8", body: `{"name":"Ada","email":"ada@example.com"}`},
+		{name: "cbor", contentType: "application/cbor", body: cbor.String()},
+		{name: "empty", body: "", code: response.CodeEmptyBody},
+		{name: "unknown field", body: `{"name":"Ada","admin":true}`, code: response.CodeInvalidBody},
+		{name: "trailing data", body: `{"name":"Ada"}{"name":"Grace"}`, code: response.CodeInvalidBody},
+		{name: "malformed", body: `{"name":`, code: response.CodeInvalidBody},
+		{name: "too large", body: `{"name":"` + strings.Repeat("a", 100) + `"}`, code: response.CodePayloadTooLarge},
+		{name: "unsupported type", contentType: "text/plain", body: "Ada", code: response.CodeUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/students", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 64)
+
+			var dst student
+			problem := Decode(r, &dst)
+			if tt.code == "" {
+				if problem != nil {
+					t.Fatalf("Decode() = %v, want nil", problem)
+				}
+				if dst.Name != "Ada" || dst.Email != "ada@example.com" {
+					t.Fatalf("decoded %+v", dst)
+				}
+				return
+			}
+			if problem == nil || problem.Code != tt.code {
+				t.Fatalf("Decode() = %v, want a %s problem", problem, tt.code)
+			}
+		})
+	}
+}
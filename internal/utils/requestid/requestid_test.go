@@ -0,0 +1,49 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "", want: false},
+		{id: "abc-123", want: true},
+		{id: "3f2a9c1e-0b7d-4c55-9e1a-6d2b8f4a7c90", want: true},
+		{id: "!~", want: true},
+		{id: "has space", want: false},
+		{id: "line\nbreak", want: false},
+		{id: "ünicode", want: false},
+		{id: strings.Repeat("a", maxLength), want: true},
+		{id: strings.Repeat("a", maxLength+1), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	if len(a) != 32 || !Valid(a) {
+		t.Fatalf("New() = %q, want 32 hex characters", a)
+	}
+	if a == b {
+		t.Fatalf("New() returned %q twice", a)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Fatalf("FromContext(empty) = %q, want \"\"", id)
+	}
+	if id := FromContext(WithContext(context.Background(), "abc")); id != "abc" {
+		t.Fatalf("FromContext() = %q, want abc", id)
+	}
+}
@@ -2,52 +2,193 @@ package response
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/codec"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/logger"
+	"github.com/Saidurbu/go-lang-crud/internal/utils/validation"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
 const (
-	StatusOk                  = "ok"
-	StatusCreated             = "created"
-	StatusBadRequest          = "bad request"
-	StatusInternalServerError = "internal server error"
-	StatusError               = "error"
+	ContentTypeProblem    = "application/problem+json"
+	ContentTypeProblemXML = "application/problem+xml"
 )
 
-type Response struct {
-	Status  string `json:"status"`
+// Stable, machine-readable error codes carried in Problem.Code. Clients
+// should branch on these rather than on Title or Detail.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeEmptyBody            = "empty_body"
+	CodeInvalidBody          = "invalid_body"
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidQuery         = "invalid_query"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeRateLimited          = "rate_limited"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeInternal             = "internal_error"
+)
+
+// Problem is the single error representation returned by the API, rendered
+// as an RFC 7807 problem document in the format negotiated from Accept.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a single request field failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%s: %s", p.Code, p.Detail)
+	}
+	return p.Code
+}
+
+// XMLRoot names the root element of the application/problem+xml form.
+func (p *Problem) XMLRoot() xml.Name {
+	return xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}
+}
+
+func NewProblem(status int, code string, detail string) *Problem {
+	return &Problem{
+		Type:   "/problems/" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
 func WriteJSON(w http.ResponseWriter, status int, data interface{}) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	return json.NewEncoder(w).Encode(data)
 }
 
-func GeneralError(err error) Response {
-	return Response{
-		Status:  StatusError,
-		Message: err.Error(),
+// Negotiate picks the codec for the response to r from its Accept header,
+// returning a 406 problem when none of the accepted media types is supported.
+func Negotiate(r *http.Request) (codec.Codec, *Problem) {
+	c, ok := codec.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		return nil, NewProblem(http.StatusNotAcceptable, CodeNotAcceptable,
+			fmt.Sprintf("none of the accepted media types is supported, use one of %s", strings.Join(codec.Supported(), ", ")))
 	}
+	return c, nil
 }
 
-func ValidationError(errs validator.ValidationErrors) Response {
-	var errMsgs []string
-	for _, err := range errs {
+// Render writes data in the format negotiated from r's Accept header.
+func Render(w http.ResponseWriter, r *http.Request, status int, data interface{}) error {
+	c, problem := Negotiate(r)
+	if problem != nil {
+		return WriteProblem(w, r, problem)
+	}
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("Content-Type", c.MediaType())
+	w.WriteHeader(status)
+	return c.Encode(w, data)
+}
 
-		switch err.ActualTag() {
-		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("Field '%s' is required", err.Field()))
-		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("Field '%s': %s", err.Field(), err.Tag()))
+// WriteProblem renders p, defaulting its instance to the request path. It
+// falls back to JSON when the client accepts no supported format, since
+// problems must be reported somehow.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) error {
+	c := codec.JSON
+	if r != nil {
+		if p.Instance == "" {
+			p.Instance = r.URL.Path
 		}
+		if negotiated, ok := codec.Negotiate(r.Header.Get("Accept")); ok {
+			c = negotiated
+		}
+		w.Header().Add("Vary", "Accept")
+	}
+
+	switch c {
+	case codec.JSON:
+		w.Header().Set("Content-Type", ContentTypeProblem)
+	case codec.XML:
+		w.Header().Set("Content-Type", ContentTypeProblemXML)
+	default:
+		w.Header().Set("Content-Type", c.MediaType())
+	}
+	w.WriteHeader(p.Status)
+	return c.Encode(w, p)
+}
+
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code string, err error) error {
+	return WriteProblem(w, r, NewProblem(status, code, err.Error()))
+}
+
+// WriteStorageError maps err to a problem via ProblemFromError and renders it,
+// logging the underlying error when it is hidden behind a 500.
+func WriteStorageError(w http.ResponseWriter, r *http.Request, err error) error {
+	p := ProblemFromError(err)
+	if p.Status == http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Error("Unhandled error", slog.String("error", err.Error()))
 	}
-	return Response{
-		Status:  StatusBadRequest,
-		Message: strings.Join(errMsgs, ", "),
+	return WriteProblem(w, r, p)
+}
+
+// ProblemFromError is the central mapping from domain errors to HTTP status
+// codes. Unrecognised errors become a 500 with a generic detail.
+func ProblemFromError(err error) *Problem {
+	var problem *Problem
+	switch {
+	case errors.As(err, &problem):
+		return problem
+	case errors.Is(err, storage.ErrNotFound):
+		return NewProblem(http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, storage.ErrConflict):
+		return NewProblem(http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, storage.ErrValidation):
+		return NewProblem(http.StatusBadRequest, CodeValidationFailed, err.Error())
+	default:
+		return NewProblem(http.StatusInternalServerError, CodeInternal, "internal server error")
+	}
+}
+
+// WriteValidationError renders errs with messages in the language negotiated
+// from the request's Accept-Language header.
+func WriteValidationError(w http.ResponseWriter, r *http.Request, errs validator.ValidationErrors) error {
+	trans := validation.Translator(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", trans.Locale())
+	return WriteProblem(w, r, ValidationProblem(errs, trans))
+}
+
+func ValidationProblem(errs validator.ValidationErrors, trans ut.Translator) *Problem {
+	p := NewProblem(http.StatusBadRequest, CodeValidationFailed, "request body failed validation")
+	for _, err := range errs {
+		p.Errors = append(p.Errors, FieldError{
+			Field:   err.Field(),
+			Code:    err.Tag(),
+			Message: err.Translate(trans),
+		})
 	}
+	return p
 }
@@ -0,0 +1,127 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/storage"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{accept: "", status: http.StatusOK, contentType: "application/json", body: `{"name":"Ada"}`},
+		{accept: "application/xml", status: http.StatusOK, contentType: "application/xml", body: "<response><name>Ada</name></response>"},
+		{accept: "application/msgpack", status: http.StatusOK, contentType: "application/msgpack", body: "\x81\xa4name\xa3Ada"},
+		{accept: "application/cbor", status: http.StatusOK, contentType: "application/cbor", body: "\xa1dnamecAda"},
+		{accept: "text/html", status: http.StatusNotAcceptable, contentType: ContentTypeProblem, body: `"code":"not_acceptable"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/students/1", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			Render(w, r, http.StatusOK, map[string]string{"name": "Ada"})
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept" {
+				t.Fatalf("Vary = %q, want Accept", got)
+			}
+			if !strings.Contains(w.Body.String(), tt.body) {
+				t.Fatalf("body = %q, want it to contain %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{name: "json by default", contentType: ContentTypeProblem, body: `"instance":"/api/students/9"`},
+		{name: "xml when asked", accept: "application/xml", contentType: ContentTypeProblemXML, body: `<problem xmlns="urn:ietf:rfc:7807">`},
+		{name: "json for unsupported types", accept: "text/html", contentType: ContentTypeProblem, body: `"code":"not_found"`},
+		{name: "binary codecs keep their type", accept: "application/cbor", contentType: "application/cbor", body: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/students/9", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			WriteProblem(w, r, NewProblem(http.StatusNotFound, CodeNotFound, "student 9 not found"))
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.Contains(w.Body.String(), tt.body) {
+				t.Fatalf("body = %q, want it to contain %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestWriteProblemKeepsInstance(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/students/9", nil)
+	p := NewProblem(http.StatusConflict, CodeConflict, "")
+	p.Instance = "/api/students/9/restore"
+
+	WriteProblem(httptest.NewRecorder(), r, p)
+
+	if p.Instance != "/api/students/9/restore" {
+		t.Fatalf("instance = %q, want it left alone", p.Instance)
+	}
+}
+
+func TestProblemFromError(t *testing.T) {
+	custom := NewProblem(http.StatusTooManyRequests, CodeRateLimited, "slow down")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{name: "not found", err: fmt.Errorf("student 3: %w", storage.ErrNotFound), status: http.StatusNotFound, code: CodeNotFound, detail: "student 3: not found"},
+		{name: "conflict", err: fmt.Errorf("email taken: %w", storage.ErrConflict), status: http.StatusConflict, code: CodeConflict, detail: "email taken: conflict"},
+		{name: "validation", err: storage.ErrValidation, status: http.StatusBadRequest, code: CodeValidationFailed, detail: "validation failed"},
+		{name: "wrapped problem", err: fmt.Errorf("limiter: %w", custom), status: http.StatusTooManyRequests, code: CodeRateLimited, detail: "slow down"},
+		{name: "anything else is hidden", err: errors.New("pq: connection refused"), status: http.StatusInternalServerError, code: CodeInternal, detail: "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ProblemFromError(tt.err)
+			if p.Status != tt.status || p.Code != tt.code || p.Detail != tt.detail {
+				t.Fatalf("ProblemFromError() = %d %s %q, want %d %s %q", p.Status, p.Code, p.Detail, tt.status, tt.code, tt.detail)
+			}
+		})
+	}
+}
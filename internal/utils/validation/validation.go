@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"unicode"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	de_translations "github.com/go-playground/validator/v10/translations/de"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	"golang.org/x/text/language"
+)
+
+const passwordMinLength = 8
+
+// passwordMessages holds the translation of the custom "password" tag for
+// every supported locale.
+var passwordMessages = map[string]string{
+	"en": "{0} must be at least 8 characters and contain an upper case letter, a lower case letter and a digit",
+	"de": "{0} muss mindestens 8 Zeichen lang sein und einen Groß- und einen Kleinbuchstaben sowie eine Ziffer enthalten",
+	"es": "{0} debe tener al menos 8 caracteres y contener una letra mayúscula, una letra minúscula y un dígito",
+	"fr": "{0} doit contenir au moins 8 caractères dont une lettre majuscule, une lettre minuscule et un chiffre",
+}
+
+var (
+	validate *validator.Validate
+	uni      *ut.UniversalTranslator
+)
+
+// The validator caches struct metadata, so a single instance is shared by
+// every handler instead of calling validator.New per request.
+func init() {
+	validate = validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.RegisterValidation("password", strongPassword); err != nil {
+		panic(err)
+	}
+
+	english := en.New()
+	uni = ut.New(english, english, de.New(), es.New(), fr.New())
+
+	register := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"de": de_translations.RegisterDefaultTranslations,
+		"es": es_translations.RegisterDefaultTranslations,
+		"fr": fr_translations.RegisterDefaultTranslations,
+	}
+	for locale, registerDefaults := range register {
+		trans, _ := uni.GetTranslator(locale)
+		if err := registerDefaults(validate, trans); err != nil {
+			panic(err)
+		}
+		if err := registerPasswordTranslation(trans, passwordMessages[locale]); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Struct validates s against its `validate` tags.
+func Struct(s interface{}) error {
+	return validate.Struct(s)
+}
+
+// newStudent requires the password that types.Student leaves optional so
+// updates can keep the current one.
+type newStudent struct {
+	types.Student
+	Password string `validate:"required"`
+}
+
+// NewStudent validates a student being created, which must have a password.
+func NewStudent(student types.Student) error {
+	return validate.Struct(newStudent{Student: student, Password: student.Password})
+}
+
+// Translator picks the best supported translator for an Accept-Language
+// header value, falling back to English.
+func Translator(acceptLanguage string) ut.Translator {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+
+	locales := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		locales = append(locales, base.String())
+	}
+
+	trans, _ := uni.FindTranslator(locales...)
+	return trans
+}
+
+func registerPasswordTranslation(trans ut.Translator, message string) error {
+	return validate.RegisterTranslation("password", trans,
+		func(ut ut.Translator) error {
+			return ut.Add("password", message, true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T("password", fe.Field())
+			return t
+		},
+	)
+}
+
+func strongPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len(password) < passwordMinLength {
+		return false
+	}
+
+	var upper, lower, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Saidurbu/go-lang-crud/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestStruct(t *testing.T) {
+	valid := types.Student{Name: "Ada", Email: "ada@example.com", Password: "Passw0rdX", Age: 20}
+
+	tests := []struct {
+		name   string
+		modify func(s *types.Student)
+		failed []string
+	}{
+		{name: "valid", modify: func(s *types.Student) {}},
+		{name: "password optional", modify: func(s *types.Student) { s.Password = "" }},
+		{name: "name too short", modify: func(s *types.Student) { s.Name = "A" }, failed: []string{"Name:min"}},
+		{name: "name too long", modify: func(s *types.Student) { s.Name = strings.Repeat("a", 101) }, failed: []string{"Name:max"}},
+		{name: "email missing", modify: func(s *types.Student) { s.Email = "" }, failed: []string{"Email:required"}},
+		{name: "email malformed", modify: func(s *types.Student) { s.Email = "ada.example.com" }, failed: []string{"Email:email"}},
+		{name: "age zero", modify: func(s *types.Student) { s.Age = 0 }, failed: []string{"Age:gte"}},
+		{name: "age too high", modify: func(s *types.Student) { s.Age = 121 }, failed: []string{"Age:lte"}},
+		{name: "password too short", modify: func(s *types.Student) { s.Password = "Pa55wor" }, failed: []string{"Password:password"}},
+		{name: "password without digit", modify: func(s *types.Student) { s.Password = "Password" }, failed: []string{"Password:password"}},
+		{name: "password without upper case", modify: func(s *types.Student) { s.Password = "passw0rd" }, failed: []string{"Password:password"}},
+		{name: "password without lower case", modify: func(s *types.Student) { s.Password = "PASSW0RD" }, failed: []string{"Password:password"}},
+		{name: "unicode password", modify: func(s *types.Student) { s.Password = "Ünïcöde9" }},
+		{name: "password at the bcrypt limit", modify: func(s *types.Student) { s.Password = "Passw0rd" + strings.Repeat("x", 64) }},
+		{name: "password over the bcrypt limit", modify: func(s *types.Student) { s.Password = "Passw0rd" + strings.Repeat("x", 65) }, failed: []string{"Password:max"}},
+		{
+			name:   "several fields",
+			modify: func(s *types.Student) { s.Name, s.Age = "", 0 },
+			failed: []string{"Name:required", "Age:gte"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			student := valid
+			tt.modify(&student)
+
+			var failed []string
+			var errs validator.ValidationErrors
+			if err := Struct(student); errors.As(err, &errs) {
+				for _, fe := range errs {
+					failed = append(failed, fe.Field()+":"+fe.Tag())
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(failed, tt.failed) {
+				t.Fatalf("failed = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestNewStudent(t *testing.T) {
+	tests := []struct {
+		name    string
+		student types.Student
+		failed  []string
+		message string
+	}{
+		{
+			name:    "valid",
+			student: types.Student{Name: "Ada", Email: "ada@example.com", Password: "Passw0rdX", Age: 20},
+		},
+		{
+			name:    "password missing",
+			student: types.Student{Name: "Ada", Email: "ada@example.com", Age: 20},
+			failed:  []string{"Password:required"},
+			message: "Password is a required field",
+		},
+		{
+			name:    "password weak",
+			student: types.Student{Name: "Ada", Email: "ada@example.com", Password: "weak", Age: 20},
+			failed:  []string{"Password:password"},
+		},
+		{
+			name:    "several fields",
+			student: types.Student{Email: "ada@example.com", Age: 20},
+			failed:  []string{"Name:required", "Password:required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failed []string
+			var errs validator.ValidationErrors
+			if err := NewStudent(tt.student); errors.As(err, &errs) {
+				for _, fe := range errs {
+					failed = append(failed, fe.Field()+":"+fe.Tag())
+				}
+				if tt.message != "" {
+					if got := errs[0].Translate(Translator("en")); got != tt.message {
+						t.Errorf("message = %q, want %q", got, tt.message)
+					}
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(failed, tt.failed) {
+				t.Fatalf("failed = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestTranslator(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		locale         string
+		message        string
+	}{
+		{acceptLanguage: "", locale: "en", message: "Password must be at least 8 characters"},
+		{acceptLanguage: "de-DE,de;q=0.9", locale: "de", message: "Password muss mindestens 8 Zeichen"},
+		{acceptLanguage: "ja, fr;q=0.5", locale: "fr", message: "Password doit contenir au moins 8 caractères"},
+		{acceptLanguage: "es-MX", locale: "es", message: "Password debe tener al menos 8 caracteres"},
+		{acceptLanguage: "ja", locale: "en", message: "Password must be at least 8 characters"},
+		{acceptLanguage: "not a header", locale: "en", message: "Password must be at least 8 characters"},
+	}
+
+	var errs validator.ValidationErrors
+	err := Struct(types.Student{Name: "Ada", Email: "ada@example.com", Password: "weak", Age: 20})
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct() = %v, want validation errors", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			trans := Translator(tt.acceptLanguage)
+			if trans.Locale() != tt.locale {
+				t.Fatalf("locale = %q, want %q", trans.Locale(), tt.locale)
+			}
+			if got := errs[0].Translate(trans); !strings.HasPrefix(got, tt.message) {
+				t.Fatalf("message = %q, want it to start with %q", got, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{index: 0, want: "A"},
+		{index: 7, want: "H"},
+		{index: 25, want: "Z"},
+		{index: 26, want: "AA"},
+		{index: 51, want: "AZ"},
+		{index: 52, want: "BA"},
+		{index: 701, want: "ZZ"},
+		{index: 702, want: "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := columnName(tt.index); got != tt.want {
+			t.Errorf("columnName(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Students & co")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]any{
+		{"id", "name", "deleted_at"},
+		{uint(1), "Ada <Lovelace>", nil},
+		{2, "  padded  ", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string][]byte)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("workbook has no %s", name)
+		}
+		if err := xml.Unmarshal(parts[name], new(struct{})); err != nil {
+			t.Fatalf("%s is not well-formed: %v", name, err)
+		}
+	}
+	if !bytes.Contains(parts["xl/workbook.xml"], []byte(`name="Students &amp; co"`)) {
+		t.Errorf("sheet name is missing or unescaped: %s", parts["xl/workbook.xml"])
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Ref   string `xml:"r,attr"`
+			Cells []struct {
+				Ref   string `xml:"r,attr"`
+				Type  string `xml:"t,attr"`
+				Value string `xml:"v"`
+				Text  string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+
+	type cell struct{ ref, typ, value string }
+	want := [][]cell{
+		{{"A1", "inlineStr", "id"}, {"B1", "inlineStr", "name"}, {"C1", "inlineStr", "deleted_at"}},
+		{{"A2", "", "1"}, {"B2", "inlineStr", "Ada <Lovelace>"}},
+		{{"A3", "", "2"}, {"B3", "inlineStr", "  padded  "}, {"C3", "inlineStr", "2026-01-02T03:04:05Z"}},
+	}
+	if len(sheet.Rows) != len(want) {
+		t.Fatalf("sheet has %d rows, want %d", len(sheet.Rows), len(want))
+	}
+	for i, row := range sheet.Rows {
+		if len(row.Cells) != len(want[i]) {
+			t.Fatalf("row %s has %d cells, want %d", row.Ref, len(row.Cells), len(want[i]))
+		}
+		for j, c := range row.Cells {
+			got := cell{c.Ref, c.Type, c.Value + c.Text}
+			if got != want[i][j] {
+				t.Errorf("cell = %+v, want %+v", got, want[i][j])
+			}
+		}
+	}
+}
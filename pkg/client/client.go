@@ -0,0 +1,343 @@
+// Package client is a typed Go client for the student CRUD API.
+//
+// A Client logs in on demand with the credentials given to WithCredentials,
+// logs in again shortly before its token expires or when the server rejects
+// it, and retries requests that failed transiently with exponential backoff.
+// Failed requests return *Error, which matches the sentinel errors in this
+// package through errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// refreshBefore is how long before its expiry a token is replaced.
+const refreshBefore = time.Minute
+
+// Client calls the API at a base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	userAgent  string
+
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu       sync.Mutex
+	email    string
+	password string
+	token    string
+	expiry   time.Time
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to configure TLS or timeouts.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithCredentials lets the client log in, and log in again, by itself.
+func WithCredentials(email, password string) Option {
+	return func(c *Client) { c.email, c.password = email, password }
+}
+
+// WithToken starts the client with an existing bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) { c.setToken(token) }
+}
+
+// WithRetries sets how often a transiently failed request is retried and the
+// bounds of the exponential backoff between attempts.
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries, c.minBackoff, c.maxBackoff = maxRetries, minBackoff, maxBackoff
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New returns a client for the API served at baseURL, e.g.
+// "https://students.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q: scheme and host are required", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		userAgent:  "go-lang-crud-client",
+		maxRetries: 3,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token returns the current bearer token, which may be empty.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// request describes a single API call.
+type request struct {
+	method  string
+	path    string
+	query   url.Values
+	body    any
+	header  http.Header
+	auth    bool
+	retried bool // true once a rejected token has been replaced
+}
+
+// do sends req, decoding a successful JSON response into out when it is not
+// nil. Requests are retried when it is safe to do so.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, payload)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil {
+				io.Copy(io.Discard, resp.Body)
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+			return nil
+		}
+
+		var apiErr *Error
+		if err == nil {
+			apiErr = decodeError(resp)
+			resp.Body.Close()
+
+			// A rejected token is replaced once, without counting as a retry.
+			if apiErr.StatusCode == http.StatusUnauthorized && req.auth && !req.retried && c.canLogin() {
+				req.retried = true
+				if err := c.login(ctx); err != nil {
+					return err
+				}
+				attempt--
+				continue
+			}
+			err = apiErr
+		}
+
+		if attempt >= c.maxRetries || !c.retryable(req, err) {
+			return err
+		}
+
+		wait := c.backoff(attempt)
+		if apiErr != nil && apiErr.StatusCode == http.StatusTooManyRequests {
+			if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+				wait = time.Duration(seconds) * time.Second
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, payload []byte) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	if req.auth {
+		token, err := c.validToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// retryable reports whether a failed attempt may be repeated. POST is only
+// retried when it carries an Idempotency-Key, since the server may already
+// have acted on it.
+func (c *Client) retryable(req request, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrUnauthorized) {
+		return false
+	}
+
+	safe := req.method != http.MethodPost || req.header.Get("Idempotency-Key") != ""
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// Transport errors; the request may or may not have arrived.
+		return safe
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests:
+		// Rate limited requests were rejected before reaching the handler.
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return safe
+	}
+	return false
+}
+
+// backoff returns an exponentially growing delay with full jitter.
+func (c *Client) backoff(attempt int) time.Duration {
+	ceiling := float64(c.minBackoff) * math.Pow(2, float64(attempt))
+	if ceiling > float64(c.maxBackoff) {
+		ceiling = float64(c.maxBackoff)
+	}
+	return time.Duration(mathrand.Int64N(int64(ceiling) + 1))
+}
+
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(body, apiErr) != nil || apiErr.Code == "" {
+		apiErr = &Error{Detail: strings.TrimSpace(string(body))}
+	}
+	apiErr.StatusCode = resp.StatusCode
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = strings.ToLower(strings.ReplaceAll(apiErr.Title, " ", "_"))
+	}
+	return apiErr
+}
+
+func (c *Client) canLogin() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.email != ""
+}
+
+// validToken returns a token that is not about to expire, logging in first
+// when needed and possible.
+func (c *Client) validToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, expiry, canLogin := c.token, c.expiry, c.email != ""
+	c.mu.Unlock()
+
+	stale := token == "" || (!expiry.IsZero() && time.Until(expiry) < refreshBefore)
+	if !stale || !canLogin {
+		if token == "" {
+			return "", fmt.Errorf("client has no token and no credentials: %w", ErrUnauthorized)
+		}
+		return token, nil
+	}
+
+	if err := c.login(ctx); err != nil {
+		return "", err
+	}
+	return c.Token(), nil
+}
+
+func (c *Client) login(ctx context.Context) error {
+	c.mu.Lock()
+	email, password := c.email, c.password
+	c.mu.Unlock()
+
+	_, err := c.Login(ctx, email, password)
+	return err
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	c.expiry = tokenExpiry(token)
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it; only the
+// server can do that. It returns the zero time when there is no claim.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// newIdempotencyKey returns a random key so that retried creations are
+// applied at most once.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,249 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithToken("token"), WithRetries(2, time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestGetStudent(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/students/7" {
+			t.Errorf("path = %q, want /api/students/7", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q, want Bearer token", got)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": 7, "name": "Ada", "email": "ada@example.com", "age": 30, "role": "student",
+			"created_at": created, "updated_at": created,
+		})
+	})
+
+	student, err := c.GetStudent(context.Background(), 7, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Student{ID: 7, Name: "Ada", Email: "ada@example.com", Age: 30, Role: "student", CreatedAt: created, UpdatedAt: created}
+	if student != want {
+		t.Fatalf("GetStudent() = %+v, want %+v", student, want)
+	}
+}
+
+func TestProblemErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		problem  map[string]any
+		sentinel error
+		code     string
+	}{
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			problem:  map[string]any{"status": 404, "title": "Not Found", "code": "not_found", "detail": "student not found"},
+			sentinel: ErrNotFound,
+			code:     "not_found",
+		},
+		{
+			name:   "validation",
+			status: http.StatusBadRequest,
+			problem: map[string]any{"status": 400, "title": "Bad Request", "code": "validation_failed",
+				"errors": []map[string]string{{"field": "email", "code": "email", "message": "email must be a valid email"}}},
+			sentinel: ErrValidation,
+			code:     "validation_failed",
+		},
+		{
+			name:     "conflict",
+			status:   http.StatusConflict,
+			problem:  map[string]any{"status": 409, "title": "Conflict", "code": "conflict"},
+			sentinel: ErrConflict,
+			code:     "conflict",
+		},
+		{
+			name:     "forbidden",
+			status:   http.StatusForbidden,
+			problem:  map[string]any{"status": 403, "title": "Forbidden", "code": "forbidden"},
+			sentinel: ErrForbidden,
+			code:     "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(tt.problem)
+			})
+
+			_, err := c.GetStudent(context.Background(), 1, false)
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("error %v does not match %v", err, tt.sentinel)
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error %T is not *Error", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.code {
+				t.Fatalf("got status %d code %q, want %d %q", apiErr.StatusCode, apiErr.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(*Client) error
+		status   int
+		attempts int32
+	}{
+		{
+			name:     "get retried on 503",
+			call:     func(c *Client) error { _, err := c.GetStudent(context.Background(), 1, false); return err },
+			status:   http.StatusServiceUnavailable,
+			attempts: 3,
+		},
+		{
+			name:     "idempotent create retried on 503",
+			call:     func(c *Client) error { _, err := c.CreateStudent(context.Background(), StudentInput{}); return err },
+			status:   http.StatusServiceUnavailable,
+			attempts: 3,
+		},
+		{
+			name:     "register not retried on 503",
+			call:     func(c *Client) error { _, err := c.Register(context.Background(), StudentInput{}); return err },
+			status:   http.StatusServiceUnavailable,
+			attempts: 1,
+		},
+		{
+			name:     "client errors not retried",
+			call:     func(c *Client) error { _, err := c.GetStudent(context.Background(), 1, false); return err },
+			status:   http.StatusNotFound,
+			attempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				writeJSON(w, tt.status, map[string]any{"status": tt.status, "code": "error"})
+			})
+
+			if err := tt.call(c); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Fatalf("server saw %d attempts, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestStudentsPaginates(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		opts  ListOptions
+		pages int
+	}{
+		{name: "empty", total: 0, opts: ListOptions{Limit: 2}, pages: 1},
+		{name: "short last page", total: 5, opts: ListOptions{Limit: 2}, pages: 3},
+		{name: "full last page", total: 4, opts: ListOptions{Limit: 2}, pages: 3},
+		{name: "starting offset", total: 6, opts: ListOptions{Limit: 2, Offset: 3}, pages: 2},
+		{name: "default page size", total: 150, pages: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages int
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				pages++
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				if limit <= 0 || limit > 100 {
+					t.Errorf("limit = %d, want 1 to 100", limit)
+				}
+
+				page := []Student{}
+				for id := offset + 1; id <= tt.total && len(page) < limit; id++ {
+					page = append(page, Student{ID: uint(id)})
+				}
+				writeJSON(w, http.StatusOK, page)
+			})
+
+			var ids []uint
+			for student, err := range c.Students(context.Background(), tt.opts) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, student.ID)
+			}
+
+			want := tt.total - tt.opts.Offset
+			if len(ids) != want {
+				t.Fatalf("got %d students, want %d", len(ids), want)
+			}
+			for i, id := range ids {
+				if id != uint(tt.opts.Offset+i+1) {
+					t.Fatalf("student %d has id %d, want %d", i, id, tt.opts.Offset+i+1)
+				}
+			}
+			if pages != tt.pages {
+				t.Fatalf("fetched %d pages, want %d", pages, tt.pages)
+			}
+		})
+	}
+}
+
+func TestStudentsStopsOnError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "" {
+			writeJSON(w, http.StatusOK, []Student{{ID: 1}, {ID: 2}})
+			return
+		}
+		writeJSON(w, http.StatusForbidden, map[string]any{"status": 403, "code": "forbidden"})
+	})
+
+	var got []uint
+	var gotErr error
+	for student, err := range c.Students(context.Background(), ListOptions{Limit: 2}) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, student.ID)
+	}
+
+	if len(got) != 2 || !errors.Is(gotErr, ErrForbidden) {
+		t.Fatalf("got students %v and error %v, want 2 students then ErrForbidden", got, gotErr)
+	}
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Profile is the authenticated student as returned by Profile.
+type Profile struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Age       int       `json:"age"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type createdResponse struct {
+	ID uint `json:"id"`
+}
+
+// Register creates a student account without authentication.
+func (c *Client) Register(ctx context.Context, input StudentInput) (uint, error) {
+	var created createdResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/registration", body: input}, &created)
+	return created.ID, err
+}
+
+// Login exchanges credentials for a bearer token, which the client then uses
+// for authenticated calls.
+func (c *Client) Login(ctx context.Context, email, password string) (string, error) {
+	var out struct {
+		Token string `json:"token"`
+	}
+	body := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/api/login", body: body}, &out); err != nil {
+		return "", err
+	}
+
+	c.setToken(out.Token)
+	return out.Token, nil
+}
+
+// Profile returns the authenticated student.
+func (c *Client) Profile(ctx context.Context) (Profile, error) {
+	var profile Profile
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/profile", auth: true}, &profile)
+	return profile, err
+}
+
+// ListStudents returns the students matching opts, or the page of them
+// selected by opts.Limit and opts.Offset.
+func (c *Client) ListStudents(ctx context.Context, opts ListOptions) ([]Student, error) {
+	var students []Student
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/students", query: opts.values(), auth: true}, &students)
+	if err != nil {
+		return nil, err
+	}
+	return students, nil
+}
+
+// Students iterates over every student matching opts, fetching pages as the
+// loop advances until one comes back short. Iteration stops after yielding
+// the first error.
+func (c *Client) Students(ctx context.Context, opts ListOptions) iter.Seq2[Student, error] {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultPageSize
+	}
+	return func(yield func(Student, error) bool) {
+		page := opts
+		for {
+			students, err := c.ListStudents(ctx, page)
+			if err != nil {
+				yield(Student{}, err)
+				return
+			}
+			for _, student := range students {
+				if !yield(student, nil) {
+					return
+				}
+			}
+			if len(students) < page.Limit {
+				return
+			}
+			page.Offset += len(students)
+		}
+	}
+}
+
+// defaultPageSize is how many students Students fetches at a time when
+// ListOptions.Limit is not set; it is the most the API serves per page.
+const defaultPageSize = 100
+
+// GetStudent returns the student with id. includeDeleted also finds
+// soft-deleted students and requires the admin role.
+func (c *Client) GetStudent(ctx context.Context, id uint, includeDeleted bool) (Student, error) {
+	var query url.Values
+	if includeDeleted {
+		query = url.Values{"include_deleted": {"true"}}
+	}
+
+	var student Student
+	err := c.do(ctx, request{method: http.MethodGet, path: studentPath(id), query: query, auth: true}, &student)
+	return student, err
+}
+
+// CreateStudent creates a student. Each call is sent with its own
+// Idempotency-Key, so retries after network errors never create duplicates.
+func (c *Client) CreateStudent(ctx context.Context, input StudentInput) (uint, error) {
+	req := request{
+		method: http.MethodPost,
+		path:   "/api/students",
+		body:   input,
+		header: http.Header{"Idempotency-Key": {newIdempotencyKey()}},
+		auth:   true,
+	}
+
+	var created createdResponse
+	err := c.do(ctx, req, &created)
+	return created.ID, err
+}
+
+// UpdateStudent replaces the fields of the student with id. An empty
+// password keeps the current one.
+func (c *Client) UpdateStudent(ctx context.Context, id uint, input StudentInput) error {
+	return c.do(ctx, request{method: http.MethodPut, path: studentPath(id), body: input, auth: true}, nil)
+}
+
+// DeleteStudent soft-deletes the student with id.
+func (c *Client) DeleteStudent(ctx context.Context, id uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: studentPath(id), auth: true}, nil)
+}
+
+// RestoreStudent undoes the soft deletion of the student with id. It
+// requires the admin role.
+func (c *Client) RestoreStudent(ctx context.Context, id uint) error {
+	return c.do(ctx, request{method: http.MethodPost, path: studentPath(id) + "/restore", auth: true}, nil)
+}
+
+func studentPath(id uint) string {
+	return "/api/students/" + strconv.FormatUint(uint64(id), 10)
+}
+
+func (o ListOptions) values() url.Values {
+	query := url.Values{}
+	if o.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+	if !o.CreatedAfter.IsZero() {
+		query.Set("created_after", o.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if !o.UpdatedSince.IsZero() {
+		query.Set("updated_since", o.UpdatedSince.Format(time.RFC3339Nano))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return query
+}
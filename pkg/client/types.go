@@ -0,0 +1,37 @@
+package client
+
+import "time"
+
+// StudentInput is the body of registration, creation and update requests.
+// Password is required when creating and optional when updating.
+type StudentInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+	Age      int    `json:"age"`
+}
+
+// Student is a student as listed by the API. Password hashes are never
+// exposed through this type.
+type Student struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Age       int        `json:"age"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// ListOptions filters student listings. Zero values match everything;
+// IncludeDeleted requires the admin role. Limit, at most 100, and Offset
+// select a page of ListStudents; Students pages through the listing by
+// itself, starting at Offset and fetching Limit students at a time.
+type ListOptions struct {
+	IncludeDeleted bool
+	CreatedAfter   time.Time
+	UpdatedSince   time.Time
+	Limit          int
+	Offset         int
+}